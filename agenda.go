@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultAgendaHorizon is the amount of days the agenda shows one by one
+const defaultAgendaHorizon = 7
+
+// AgendaGroup is a heading of the agenda with the tasks due in its period
+type AgendaGroup struct {
+	Name string `json:"name"`
+	// Date is the day of the group like 2026-10-21, empty for Overdue, Later and No date
+	Date  string `json:"date,omitempty"`
+	Tasks []Task `json:"tasks"`
+}
+
+// startOfDay returns midnight of the day of t in the location of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// groupByDue groups the tasks under Overdue, Today, Tomorrow, the following days
+// until the horizon, Later and No date. The tasks of a group are sorted by their due date,
+// groups without tasks are left out
+func groupByDue(tasks []Task, now time.Time, horizon int) []AgendaGroup {
+	today := startOfDay(now)
+	groups := []AgendaGroup{{Name: "Overdue"}}
+	for day := 0; day < horizon; day++ {
+		date := today.AddDate(0, 0, day)
+		name := date.Format("Monday, Jan 2")
+		switch day {
+		case 0:
+			name = "Today"
+		case 1:
+			name = "Tomorrow"
+		}
+		groups = append(groups, AgendaGroup{Name: name, Date: date.Format("2006-01-02")})
+	}
+	later, noDate := len(groups), len(groups)+1
+	groups = append(groups, AgendaGroup{Name: "Later"}, AgendaGroup{Name: "No date"})
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Until < tasks[j].Until
+	})
+	for _, t := range tasks {
+		i := noDate
+		if t.Until != 0 {
+			until := convertDate(t.Until)
+			// rounding ignores the hour a day is shorter or longer if the daylight saving time changes
+			day := int(math.Round(startOfDay(until).Sub(today).Hours() / 24))
+			switch {
+			case until.Before(now):
+				i = 0
+			case day < horizon:
+				i = day + 1
+			default:
+				i = later
+			}
+		}
+		groups[i].Tasks = append(groups[i].Tasks, t)
+	}
+
+	result := groups[:0]
+	for _, g := range groups {
+		if len(g.Tasks) > 0 {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// RenderAgenda renders the groups of the agenda with the time the tasks are due
+// and their category aligned to the right, like "Today - 2" followed by the two tasks
+func RenderAgenda(groups []AgendaGroup) {
+	fmt.Fprintln(out)
+	for _, g := range groups {
+		fmt.Fprintf(out, "%s - %d\n", paint(theme.Category, g.Name), len(g.Tasks))
+		for _, t := range g.Tasks {
+			right := t.CategoryName
+			if t.Until != 0 {
+				layout := "Jan 2 15:04"
+				if g.Date != "" {
+					layout = "15:04"
+				}
+				right += "  " + paint(theme.Muted, convertDate(t.Until).Format(layout))
+			}
+			fmt.Fprintln(out, strings.Join(layoutLines(taskPrefix(&t), t.Description, "", right, termWidth, config.Truncate), "\n"))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// agendaCommand renders the open tasks matching the filter given by args grouped by their due date
+func agendaCommand(args []string) {
+	filter := parseFilterArgs(append([]string{"open"}, args...))
+	tasks := FilterTasks(filter, "id", "ASC")
+	if len(tasks) == 0 && !machineOutput() {
+		// nothing to do is no error, scripts get an empty list
+		fmt.Fprintln(out, "Nothing to do, there are no open tasks matching the filter")
+		return
+	}
+
+	groups := groupByDue(tasks, time.Now(), config.AgendaHorizon)
+	if machineOutput() {
+		renderJSON(groups)
+		return
+	}
+	withPager(!noPager, func() {
+		RenderAgenda(groups)
+	})
+}
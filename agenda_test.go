@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_groupByDue(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	at := func(day int, hour int) int64 {
+		return time.Date(2026, 10, 21+day, hour, 0, 0, 0, time.Local).Unix()
+	}
+	tasks := []Task{
+		{Id: 1, Until: at(10, 9)},
+		{Id: 2, Until: at(0, 15)},
+		{Id: 3},
+		{Id: 4, Until: at(-1, 12)},
+		{Id: 5, Until: at(0, 8)},
+		{Id: 6, Until: at(1, 9)},
+		{Id: 7, Until: at(3, 9)},
+		{Id: 8, Until: at(6, 23)},
+	}
+
+	tests := []struct {
+		horizon int
+		want    map[string][]int64
+		order   []string
+	}{
+		{7, map[string][]int64{
+			"Overdue":          {4, 5},
+			"Today":            {2},
+			"Tomorrow":         {6},
+			"Saturday, Oct 24": {7},
+			"Tuesday, Oct 27":  {8},
+			"Later":            {1},
+			"No date":          {3},
+		}, []string{"Overdue", "Today", "Tomorrow", "Saturday, Oct 24", "Tuesday, Oct 27", "Later", "No date"}},
+		{1, map[string][]int64{
+			"Overdue": {4, 5},
+			"Today":   {2},
+			"Later":   {6, 7, 8, 1},
+			"No date": {3},
+		}, []string{"Overdue", "Today", "Later", "No date"}},
+	}
+	for _, tt := range tests {
+		groups := groupByDue(append([]Task(nil), tasks...), now, tt.horizon)
+		var order []string
+		for _, g := range groups {
+			order = append(order, g.Name)
+			var ids []int64
+			for _, task := range g.Tasks {
+				ids = append(ids, task.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.want[g.Name]) {
+				t.Errorf("Horizon %d: got %v under %s, want %v", tt.horizon, ids, g.Name, tt.want[g.Name])
+			}
+		}
+		if !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("Horizon %d: got the groups %v, want %v", tt.horizon, order, tt.order)
+		}
+	}
+}
+
+func TestRenderAgenda(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	until := time.Date(2026, 10, 21, 15, 0, 0, 0, time.Local).Unix()
+	RenderAgenda([]AgendaGroup{
+		{Name: "Today", Date: "2026-10-21", Tasks: []Task{{Id: 4, Description: "Pay invoice", CategoryName: "home", Until: until}}},
+		{Name: "Later", Tasks: []Task{{Id: 5, Description: "Renew passport", CategoryName: "home", Until: until}}},
+	})
+
+	got := buf.String()
+	for _, want := range []string{"Today - 1\n", "     ⨉  4 Pay invoice  home  15:00\n", "Later - 1\n", "5 Renew passport  home  Oct 21 15:00\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+}
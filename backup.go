@@ -0,0 +1,601 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupVersion is the version of the backup format written by export,
+// import reads all versions up to it
+const backupVersion = 1
+
+// strategies of import for tasks and views which already exist
+const (
+	conflictKeep    = "keep"
+	conflictReplace = "replace"
+	conflictFail    = "fail"
+)
+
+// Backup is the document written by export and read by import with all data of the database.
+// Tasks reference their category by name and other tasks by their id in the backup
+type Backup struct {
+	Version    int        `json:"version"`
+	Exported   string     `json:"exported"`
+	Categories []Category `json:"categories"`
+	Tasks      []Task     `json:"tasks"`
+	Views      []View     `json:"views"`
+	// Config is the content of the config file, if there is one
+	Config json.RawMessage `json:"config,omitempty"`
+	// GithubToken is only exported if asked for
+	GithubToken string `json:"github_token,omitempty"`
+}
+
+// exchangeFormat reads and writes tasks in the format of another tool,
+// formats which can only be imported or exported leave the other function nil.
+// read gets the path given to import and returns the tasks, which reference other tasks
+// by their id in the file, and why entries got skipped
+type exchangeFormat struct {
+	write func(w io.Writer, tasks []Task) error
+	read  func(path string) ([]Task, []string, error)
+	// extension of the files in the format, export and import use the format for such files
+	extension string
+}
+
+// exchangeFormats are the formats besides the backup, given as first argument of export and import
+var exchangeFormats = map[string]exchangeFormat{
+	"todotxt":     {WriteTodoTxt, readFile(ReadTodoTxt), ""},
+	"taskbook":    {nil, ReadTaskbook, ""},
+	"taskwarrior": {WriteTaskwarrior, ReadTaskwarrior, ""},
+	"ics":         {WriteICal, ReadICal, ".ics"},
+	"markdown":    {nil, ReadMarkdown, ".md"},
+}
+
+// readFile turns a function reading tasks into the read function of an exchangeFormat
+// opening the file at path
+func readFile(read func(r io.Reader) ([]Task, error)) func(path string) ([]Task, []string, error) {
+	return func(path string) ([]Task, []string, error) {
+		f, err := openFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		tasks, err := read(f)
+		return tasks, nil, err
+	}
+}
+
+// ImportResult counts what import changed
+type ImportResult struct {
+	Added      int
+	Replaced   int
+	Skipped    int
+	Categories int
+	Views      int
+	Config     bool
+	Token      bool
+}
+
+// newBackup collects all data of the database, the GitHub token only if withToken is set
+func newBackup(withToken bool, now time.Time) Backup {
+	b := Backup{
+		Version:    backupVersion,
+		Exported:   now.Format(time.RFC3339),
+		Categories: AllCategories(),
+		Tasks:      AllTasks("id", "ASC"),
+		Views:      AllViews(),
+	}
+	if b.Tasks == nil {
+		b.Tasks = []Task{}
+	}
+	if data, err := ioutil.ReadFile(configPath); err == nil && json.Valid(data) {
+		b.Config = data
+	}
+	if withToken {
+		// a missing token is no error, there is just none to export
+		b.GithubToken, _ = getToken()
+	}
+	return b
+}
+
+// readBackup decodes a backup and checks that its version is supported
+func readBackup(r io.Reader) (Backup, error) {
+	var b Backup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return b, fmt.Errorf("invalid backup: %s", err)
+	}
+	if b.Version < 1 || b.Version > backupVersion {
+		return b, fmt.Errorf("unsupported backup version %d, expected 1 to %d", b.Version, backupVersion)
+	}
+	return b, nil
+}
+
+// readBackupFile reads the backup in the file at path, - and no path are stdin
+func readBackupFile(path string) (Backup, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return Backup{}, err
+	}
+	defer f.Close()
+	return readBackup(f)
+}
+
+// ImportBackup restores the backup. Into a database without tasks the tasks are restored with their ids,
+// otherwise they get merged and tasks with the uuid or else the description of an existing task are kept, replaced or
+// make the import fail depending on the strategy. Views are handled the same.
+// The config file and the GitHub token are only restored if there are none yet or with replace.
+// Everything is written in one transaction, so nothing is changed if the import fails
+func ImportBackup(b Backup, strategy string) (ImportResult, error) {
+	switch strategy {
+	case conflictKeep, conflictReplace, conflictFail:
+	default:
+		return ImportResult{}, fmt.Errorf("unknown conflict strategy %q, expected %s, %s or %s", strategy, conflictKeep, conflictReplace, conflictFail)
+	}
+
+	var result ImportResult
+	previousConfig, configErr := ioutil.ReadFile(configPath)
+	err := inTransaction(func() error {
+		var err error
+		if result, err = importBackup(b, strategy); err != nil {
+			return err
+		}
+		// the config is written last, so a failure of the database leaves it alone
+		if len(b.Config) > 0 && configPath != "" && (os.IsNotExist(configErr) || strategy == conflictReplace) {
+			if err := ioutil.WriteFile(configPath, b.Config, 0644); err != nil {
+				return err
+			}
+			result.Config = true
+		}
+		return nil
+	})
+	if err != nil {
+		if result.Config {
+			// the transaction failed after the config got written
+			if configErr == nil {
+				_ = ioutil.WriteFile(configPath, previousConfig, 0644)
+			} else {
+				_ = os.Remove(configPath)
+			}
+		}
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// importBackup writes the tasks, views and token of the backup, see ImportBackup
+func importBackup(b Backup, strategy string) (ImportResult, error) {
+	var result ImportResult
+	existing := newExistingTasks()
+	existingViews := make(map[string]bool)
+	for _, v := range AllViews() {
+		existingViews[v.Name] = true
+	}
+
+	if strategy == conflictFail {
+		var conflicts []string
+		for _, t := range b.Tasks {
+			if _, ok := existing.find(&t); ok {
+				conflicts = append(conflicts, strconv.Quote(t.Description))
+			}
+		}
+		for _, v := range b.Views {
+			if existingViews[v.Name] {
+				conflicts = append(conflicts, "view "+v.Name)
+			}
+		}
+		if len(conflicts) > 0 {
+			return result, fmt.Errorf("%d tasks or views already exist: %s", len(conflicts), strings.Join(conflicts, ", "))
+		}
+	}
+
+	categories := make(map[string]bool)
+	for _, c := range AllCategories() {
+		categories[c.Name] = true
+	}
+	categoryId := func(name string) (int64, error) {
+		if name == "" {
+			return defaultCategoryID, nil
+		}
+		name = strings.ToLower(name)
+		if !categories[name] {
+			categories[name] = true
+			result.Categories++
+		}
+		return GetOrCreateCategory(name)
+	}
+	for _, c := range b.Categories {
+		if _, err := categoryId(c.Name); err != nil {
+			return result, err
+		}
+	}
+
+	// ids maps the ids of the backup to the ids in the database
+	restore := len(existing.byDescription) == 0 && distinctIds(b.Tasks)
+	ids := make(map[int64]int64)
+	var written []Task
+	for _, t := range b.Tasks {
+		id, err := categoryId(t.CategoryName)
+		if err != nil {
+			return result, err
+		}
+		t.CategoryId = id
+
+		backupId := t.Id
+		if current, ok := existing.find(&t); ok {
+			ids[backupId] = current
+			if strategy == conflictKeep {
+				result.Skipped++
+				continue
+			}
+			t.Id = current
+			if err := updateTask(&t); err != nil {
+				return result, err
+			}
+			result.Replaced++
+		} else {
+			if !restore {
+				t.Id = 0
+			}
+			if err := restoreTask(&t); err != nil {
+				return result, err
+			}
+			ids[backupId] = t.Id
+			existing.add(&t)
+			result.Added++
+		}
+		written = append(written, t)
+	}
+
+	for _, t := range written {
+		var depends []int64
+		for _, d := range t.Depends {
+			if id, ok := ids[d]; ok {
+				depends = append(depends, id)
+			}
+		}
+		if _, err := db.Exec(`UPDATE tasks SET depends=$1 WHERE id=$2`, joinIds(depends), t.Id); err != nil {
+			return result, err
+		}
+	}
+
+	for _, v := range b.Views {
+		if existingViews[v.Name] && strategy == conflictKeep {
+			continue
+		}
+		if err := SaveView(v); err != nil {
+			return result, err
+		}
+		result.Views++
+	}
+
+	if b.GithubToken != "" {
+		if _, err := getToken(); err != nil || strategy == conflictReplace {
+			if err := saveGitToken(b.GithubToken); err != nil {
+				return result, err
+			}
+			result.Token = true
+		}
+	}
+	return result, nil
+}
+
+// existingTasks finds the tasks of the database an imported task conflicts with
+type existingTasks struct {
+	byUuid        map[string]int64
+	byDescription map[string]int64
+}
+
+// newExistingTasks collects the tasks of the database
+func newExistingTasks() existingTasks {
+	e := existingTasks{make(map[string]int64), make(map[string]int64)}
+	for _, t := range AllTasks("id", "ASC") {
+		e.add(&t)
+	}
+	return e
+}
+
+func (e existingTasks) add(t *Task) {
+	if t.Uuid != "" {
+		e.byUuid[t.Uuid] = t.Id
+	}
+	e.byDescription[t.Description] = t.Id
+}
+
+// find returns the id of the task with the uuid of t, so renamed tasks are found,
+// or else with its description
+func (e existingTasks) find(t *Task) (int64, bool) {
+	if id, ok := e.byUuid[t.Uuid]; ok && t.Uuid != "" {
+		return id, true
+	}
+	id, ok := e.byDescription[t.Description]
+	return id, ok
+}
+
+// distinctIds reports whether all tasks have an id and no id is used twice
+func distinctIds(tasks []Task) bool {
+	seen := make(map[int64]bool)
+	for _, t := range tasks {
+		if t.Id <= 0 || seen[t.Id] {
+			return false
+		}
+		seen[t.Id] = true
+	}
+	return true
+}
+
+// restoreTask inserts the task with all its fields except the tasks it depends on,
+// a task without id or uuid gets a new one
+func restoreTask(t *Task) error {
+	if t.Uuid == "" {
+		t.Uuid = newUuid()
+	}
+	var id interface{}
+	if t.Id > 0 {
+		id = t.Id
+	}
+	sqlStmt := `INSERT INTO tasks (id, description, created, until, done, completed, category_id, tags, notes, priority, uuid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	res, err := db.Exec(sqlStmt, id, t.Description, t.Created, t.Until, t.Done, t.Completed, t.CategoryId, strings.Join(t.Tags, " "), t.Notes, t.Priority, t.Uuid)
+	if err != nil {
+		return fmt.Errorf("could not restore task %q: %s", t.Description, err)
+	}
+	t.Id, err = res.LastInsertId()
+	return err
+}
+
+// updateTask replaces all fields of the task with the same id except the tasks it depends on
+func updateTask(t *Task) error {
+	sqlStmt := `UPDATE tasks SET description=$1, created=$2, until=$3, done=$4, completed=$5, category_id=$6, tags=$7, notes=$8, priority=$9, uuid=COALESCE(NULLIF($10, ''), uuid) WHERE id=$11`
+	_, err := db.Exec(sqlStmt, t.Description, t.Created, t.Until, t.Done, t.Completed, t.CategoryId, strings.Join(t.Tags, " "), t.Notes, t.Priority, t.Uuid, t.Id)
+	if err != nil {
+		return fmt.Errorf("could not replace task %q: %s", t.Description, err)
+	}
+	return nil
+}
+
+// openFile opens the file at path for reading, - and no path are stdin
+func openFile(path string) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// createFile creates the file at path for writing, - and no path are stdout
+func createFile(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{out}, nil
+	}
+	return os.Create(path)
+}
+
+// nopWriteCloser does not close the wrapped writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// exchangeArgs splits the arguments of export and import into the name of the format and the format,
+// which are empty for the backup, and the path of the file. Without a format given as first argument
+// or by -ics it is the one of the extension of the file
+func exchangeArgs(args []string) (string, *exchangeFormat, string) {
+	name := ""
+	if icsFormat {
+		name = "ics"
+	} else if len(args) > 0 {
+		if _, ok := exchangeFormats[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+	if len(args) > 1 {
+		log.Fatalf("Expected at most one file instead of %s\n", strings.Join(args, " "))
+	}
+	path := ""
+	if len(args) == 1 {
+		path = args[0]
+	}
+	if name == "" && path != "" {
+		for n, f := range exchangeFormats {
+			if f.extension != "" && strings.EqualFold(filepath.Ext(path), f.extension) {
+				name = n
+			}
+		}
+	}
+	if name == "" {
+		return "", nil, path
+	}
+	format := exchangeFormats[name]
+	return name, &format, path
+}
+
+// exportCommand writes the backup or the tasks in the format given as first argument
+// to the file given as argument or stdout
+func exportCommand(args []string) {
+	name, format, path := exchangeArgs(args)
+	if format != nil && format.write == nil {
+		log.Fatalf("%s can only be imported\n", name)
+	}
+
+	f, err := createFile(path)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if format != nil {
+		err = format.write(f, AllTasks("id", "ASC"))
+	} else {
+		encoder := json.NewEncoder(f)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(newBackup(exportToken, time.Now()))
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// importCommand restores the backup or merges the tasks in the format given as first argument
+// from the file given as argument or stdin
+func importCommand(args []string) {
+	name, format, path := exchangeArgs(args)
+	if format != nil && format.read == nil {
+		log.Fatalf("%s can only be exported\n", name)
+	}
+
+	var b Backup
+	var skipped []string
+	var err error
+	if format != nil {
+		b.Version = backupVersion
+		b.Tasks, skipped, err = format.read(path)
+	} else {
+		b, err = readBackupFile(path)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if dryRun {
+		for _, reason := range skipped {
+			fmt.Fprintln(out, "Would skip "+reason)
+		}
+		previewImport(b.Tasks, conflictStrategy)
+		return
+	}
+	result, err := ImportBackup(b, conflictStrategy)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for _, reason := range skipped {
+		fmt.Fprintln(out, "Skipped "+reason)
+	}
+	fmt.Fprintln(out, result)
+}
+
+// previewImport prints the tasks import would write as markdown checklists grouped by category in the order
+// they are read. Tasks which a task read before them depends on are nested below it and tasks with
+// the description of an existing task are marked with what the strategy does with them
+func previewImport(tasks []Task, strategy string) {
+	existing := newExistingTasks()
+	category := func(t Task) string {
+		if t.CategoryName == "" {
+			return "default"
+		}
+		return strings.ToLower(t.CategoryName)
+	}
+
+	index := make(map[int64]int)
+	for i, t := range tasks {
+		index[t.Id] = i
+	}
+	nested := make(map[int]bool)
+	children := make(map[int][]int)
+	for i, t := range tasks {
+		for _, id := range t.Depends {
+			if j, ok := index[id]; ok && j > i && !nested[j] && category(tasks[j]) == category(t) {
+				nested[j] = true
+				children[i] = append(children[i], j)
+			}
+		}
+	}
+
+	var categories []string
+	roots := make(map[string][]int)
+	for i, t := range tasks {
+		c := category(t)
+		if _, ok := roots[c]; !ok {
+			categories = append(categories, c)
+		}
+		if !nested[i] {
+			roots[c] = append(roots[c], i)
+		}
+	}
+
+	conflicts := 0
+	var printTask func(i int, indent string)
+	printTask = func(i int, indent string) {
+		t := tasks[i]
+		check := " "
+		if t.Done {
+			check = "x"
+		}
+		line := fmt.Sprintf("%s- [%s] %s", indent, check, strings.Join(strings.Fields(t.Description), " "))
+		if t.Until != 0 {
+			line += fmt.Sprintf(" (due %s)", convertDate(t.Until).Format("2006-01-02"))
+		}
+		if _, ok := existing.find(&t); ok {
+			conflicts++
+			switch strategy {
+			case conflictKeep:
+				line += " (exists, kept)"
+			case conflictReplace:
+				line += " (exists, replaced)"
+			default:
+				line += " (exists)"
+			}
+		}
+		fmt.Fprintln(out, line)
+		for _, c := range children[i] {
+			printTask(c, indent+"  ")
+		}
+	}
+	for i, c := range categories {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "## %s\n\n", strings.Title(c))
+		for _, r := range roots[c] {
+			printTask(r, "")
+		}
+	}
+
+	summary := fmt.Sprintf("Would import %d tasks", len(tasks)-conflicts)
+	switch {
+	case conflicts == 0:
+	case strategy == conflictKeep:
+		summary += fmt.Sprintf(" and skip %d existing tasks", conflicts)
+	case strategy == conflictReplace:
+		summary += fmt.Sprintf(" and replace %d", conflicts)
+	default:
+		summary = fmt.Sprintf("Would fail as %d tasks already exist", conflicts)
+	}
+	fmt.Fprintf(out, "\n%s, nothing was written\n", summary)
+}
+
+// String summarises the result like "Imported 3 tasks, replaced 1 and skipped 2 existing tasks"
+func (r ImportResult) String() string {
+	s := fmt.Sprintf("Imported %d tasks", r.Added)
+	if r.Replaced > 0 {
+		s += fmt.Sprintf(", replaced %d", r.Replaced)
+	}
+	if r.Skipped > 0 {
+		s += fmt.Sprintf(", skipped %d existing tasks", r.Skipped)
+	}
+	var extra []string
+	if r.Categories > 0 {
+		extra = append(extra, fmt.Sprintf("%d new categories", r.Categories))
+	}
+	if r.Views > 0 {
+		extra = append(extra, fmt.Sprintf("%d views", r.Views))
+	}
+	if r.Config {
+		extra = append(extra, "the config")
+	}
+	if r.Token {
+		extra = append(extra, "the GitHub token")
+	}
+	if len(extra) > 0 {
+		s += " with " + strings.Join(extra, ", ")
+	}
+	return s
+}
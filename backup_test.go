@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	SetDepends(idFlags{"3"}, []int64{1})
+	TaskDone(idFlags{"2"})
+	AddTags(idFlags{"1"}, []string{"weekend"})
+	if err := SaveView(View{Name: "home", Filter: "cat:home", Renderer: formatAligned}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveGitToken(strings.Repeat("a", 40)); err != nil {
+		t.Fatal(err)
+	}
+	want := AllTasks("id", "ASC")
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(newBackup(false, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "github_token") {
+		t.Errorf("Expected no token in %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"version":1`) {
+		t.Errorf("Expected the version in %s", buf.String())
+	}
+	if got := newBackup(true, time.Now()).GithubToken; got != strings.Repeat("a", 40) {
+		t.Errorf("Got the token %q, expected it if asked for", got)
+	}
+
+	cleanDatabase()
+	b, err := readBackup(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := ImportBackup(b, conflictKeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 3 || result.Categories != 2 || result.Views != 1 {
+		t.Errorf("Got %+v, expected 3 tasks, 2 categories and 1 view", result)
+	}
+	if got := AllTasks("id", "ASC"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Restored %v, want %v", got, want)
+	}
+	if _, err := GetView("home"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := ImportBackup(b, conflictFail); err == nil {
+		t.Error("Expected the existing tasks to make the import fail")
+	}
+	result, err = ImportBackup(b, conflictKeep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 0 || result.Skipped != 3 {
+		t.Errorf("Got %+v, expected the 3 existing tasks to be skipped", result)
+	}
+}
+
+func TestImportBackup_merge(t *testing.T) {
+	defer cleanDatabase()
+	SaveTask("Work", "Write report", 0, 0)
+	SaveTask("Home", "Clean Room", 0, 0)
+
+	b := Backup{Version: 1, Tasks: []Task{
+		{Id: 1, Description: "Clean Room", CategoryName: "home", Done: true, Completed: 100},
+		{Id: 2, Description: "Buy Present", CategoryName: "Family", Depends: []int64{1, 7}},
+	}}
+	result, err := ImportBackup(b, conflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 1 || result.Replaced != 1 || result.Categories != 1 {
+		t.Errorf("Got %+v, expected 1 added and 1 replaced task in 1 new category", result)
+	}
+
+	tasks := AllTasks("id", "ASC")
+	if len(tasks) != 3 || !tasks[1].Done || tasks[1].Completed != 100 {
+		t.Fatalf("Got %v, expected task 2 to be replaced", tasks)
+	}
+	if tasks[2].Id != 3 || tasks[2].CategoryName != "family" || !reflect.DeepEqual(tasks[2].Depends, []int64{2}) {
+		t.Errorf("Got %+v, expected the new task to depend on the replaced one", tasks[2])
+	}
+
+	if _, err := ImportBackup(b, "newest"); err == nil {
+		t.Error("Expected an unknown strategy to fail")
+	}
+}
+
+func TestImportBackup_uuid(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	tasks := AllTasks("id", "ASC")
+
+	// the task got renamed elsewhere, its uuid finds it
+	b := Backup{Version: 1, Tasks: []Task{
+		{Id: 1, Uuid: tasks[0].Uuid, Description: "Clean the whole Room", CategoryName: "home"},
+		{Id: 2, Uuid: "other", Description: "Add Tests", CategoryName: "coding"},
+	}}
+	if _, err := ImportBackup(b, conflictFail); err == nil {
+		t.Error("Expected the renamed task to be a conflict")
+	}
+	result, err := ImportBackup(b, conflictReplace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 0 || result.Replaced != 2 {
+		t.Errorf("Got %+v, expected 2 replaced tasks", result)
+	}
+	imported := AllTasks("id", "ASC")
+	if len(imported) != 3 || imported[0].Description != "Clean the whole Room" || imported[0].Uuid != tasks[0].Uuid {
+		t.Errorf("Got %v, expected the first task to be renamed", imported)
+	}
+
+	// tasks without uuid keep theirs
+	b = Backup{Version: 1, Tasks: []Task{{Id: 1, Description: "Buy Present", CategoryName: "home", Done: true}}}
+	if _, err := ImportBackup(b, conflictReplace); err != nil {
+		t.Fatal(err)
+	}
+	if task := AllTasks("id", "ASC")[2]; !task.Done || task.Uuid != tasks[2].Uuid {
+		t.Errorf("Got %+v, expected the uuid %q to be kept", task, tasks[2].Uuid)
+	}
+}
+
+func TestImportBackup_rollback(t *testing.T) {
+	defer cleanDatabase()
+	SaveTask("Home", "Clean Room", 0, 0)
+
+	tests := []struct {
+		name string
+		b    Backup
+	}{
+		{"invalid view", Backup{Version: 1, Tasks: []Task{{Id: 1, Description: "Buy Present", CategoryName: "family"}},
+			Views: []View{{Name: "two words"}}}},
+		{"invalid token", Backup{Version: 1, Tasks: []Task{{Id: 1, Description: "Buy Present"}}, GithubToken: "short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ImportBackup(tt.b, conflictReplace); err == nil {
+				t.Fatal("Expected the import to fail")
+			}
+			if tasks := AllTasks("id", "ASC"); len(tasks) != 1 {
+				t.Errorf("Got %v, expected the failed import to write nothing", tasks)
+			}
+			if categories := AllCategories(); len(categories) != 2 {
+				t.Errorf("Got %v, expected no new category", categories)
+			}
+		})
+	}
+
+	// the database is usable after the rollback
+	if _, err := ImportBackup(Backup{Version: 1, Tasks: []Task{{Id: 1, Description: "Buy Present"}}}, conflictKeep); err != nil {
+		t.Fatal(err)
+	}
+	if tasks := AllTasks("id", "ASC"); len(tasks) != 2 {
+		t.Errorf("Got %v, want 2 tasks", tasks)
+	}
+}
+
+func Test_readBackup(t *testing.T) {
+	for _, data := range []string{`{"version": 2}`, `{}`, `[]`} {
+		if _, err := readBackup(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected %s to be rejected", data)
+		}
+	}
+}
+
+func Test_previewImport(t *testing.T) {
+	defer cleanDatabase()
+	SaveTask("Home", "Clean Room", 0, 0)
+	setColors(false)
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	tasks := []Task{
+		{Id: 1, Description: "Send the agenda", CategoryName: "Meeting", Depends: []int64{2}},
+		{Id: 2, Description: "Draft it", CategoryName: "Meeting", Depends: []int64{3}},
+		{Id: 3, Description: "Book a room", CategoryName: "Meeting", Done: true},
+		{Id: 4, Description: "Clean Room"},
+	}
+	previewImport(tasks, conflictKeep)
+	want := `## Meeting
+
+- [ ] Send the agenda
+  - [ ] Draft it
+    - [x] Book a room
+
+## Default
+
+- [ ] Clean Room (exists, kept)
+
+Would import 3 tasks and skip 1 existing tasks, nothing was written
+`
+	if buf.String() != want {
+		t.Errorf("Got\n%s\nwant\n%s", buf.String(), want)
+	}
+	if len(AllTasks("id", "ASC")) != 1 {
+		t.Error("Expected nothing to be written")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalendarDay is a day of the calendar with the amount of tasks due on it
+type CalendarDay struct {
+	Date string `json:"date"`
+	// Due is the amount of all tasks due on the day, Done and Overdue are part of it
+	Due     int `json:"due"`
+	Done    int `json:"done"`
+	Overdue int `json:"overdue"`
+}
+
+// parseMonth parses the month of the calendar given as 2026-10, 10, oct or october,
+// months without a year are in the year of now
+func parseMonth(value string, now time.Time) (time.Time, bool) {
+	if t, err := time.ParseInLocation("2006-01", value, now.Location()); err == nil {
+		return t, true
+	}
+
+	month := 0
+	if n, err := strconv.Atoi(value); err == nil {
+		month = n
+	}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if v := strings.ToLower(value); v == name || v == name[:3] {
+			month = int(m)
+		}
+	}
+	if month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(now.Year(), time.Month(month), 1, 0, 0, 0, 0, now.Location()), true
+}
+
+// calendarDays counts the tasks due on every day of the month,
+// open tasks due before now are overdue
+func calendarDays(tasks []Task, month time.Time, now time.Time) []CalendarDay {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	days := make([]CalendarDay, first.AddDate(0, 1, -1).Day())
+	for i := range days {
+		days[i].Date = first.AddDate(0, 0, i).Format("2006-01-02")
+	}
+
+	for _, t := range tasks {
+		if t.Until == 0 {
+			continue
+		}
+		until := convertDate(t.Until).In(month.Location())
+		if until.Year() != first.Year() || until.Month() != first.Month() {
+			continue
+		}
+		d := &days[until.Day()-1]
+		d.Due++
+		if t.Done {
+			d.Done++
+		} else if until.Before(now) {
+			d.Overdue++
+		}
+	}
+	return days
+}
+
+// calendarCellWidth is the width of a day in the calendar grid
+const calendarCellWidth = 6
+
+// RenderCalendar renders the days of the month as a grid of weeks starting on Monday.
+// Every day shows the amount of tasks due next to its number, days with overdue tasks
+// use the style of open tasks and days on which all tasks are done the one of finished tasks
+func RenderCalendar(days []CalendarDay, month time.Time, now time.Time) {
+	width := 7 * calendarCellWidth
+	title := month.Format("January 2006")
+	fmt.Fprintf(out, "\n%s%s\n", strings.Repeat(" ", (width-len(title))/2), paint(theme.Header, title))
+
+	for _, name := range []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"} {
+		fmt.Fprint(out, padRight(fmt.Sprintf("%3s", name), calendarCellWidth))
+	}
+	fmt.Fprintln(out)
+
+	// Monday is the first column
+	column := (int(time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location()).Weekday()) + 6) % 7
+	fmt.Fprint(out, strings.Repeat(" ", column*calendarCellWidth))
+	today := now.Format("2006-01-02")
+	for i, d := range days {
+		day := fmt.Sprintf("%3d", i+1)
+		if d.Date == today {
+			day = " " + paint(theme.Selected, strconv.Itoa(i+1))
+			if i < 9 {
+				day = " " + day
+			}
+		}
+
+		count := ""
+		if d.Due > 0 {
+			style := ""
+			switch {
+			case d.Overdue > 0:
+				style = theme.Open
+			case d.Done == d.Due:
+				style = theme.Done
+			}
+			count = paint(style, strconv.Itoa(d.Due))
+		}
+		fmt.Fprint(out, padRight(day+" "+count, calendarCellWidth))
+
+		column++
+		if column == 7 && i < len(days)-1 {
+			fmt.Fprintln(out)
+			column = 0
+		}
+	}
+	fmt.Fprint(out, "\n\n")
+}
+
+// calendarCommand renders the calendar of the month given as first argument,
+// the remaining arguments filter the tasks. With the day flag the tasks due on the day are listed
+func calendarCommand(args []string) {
+	now := time.Now()
+	if calendarDay != "" {
+		dayCommand(args, now)
+		return
+	}
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if len(args) > 0 {
+		if m, ok := parseMonth(args[0], now); ok {
+			month, args = m, args[1:]
+		}
+	}
+
+	days := calendarDays(FilterTasks(parseFilterArgs(args), "id", "ASC"), month, now)
+	if machineOutput() {
+		renderJSON(days)
+		return
+	}
+	RenderCalendar(days, month, now)
+}
+
+// parseDay returns the unix timestamps of the start and the end of the calendar day described by value,
+// which is anything a due term accepts. Relative times like 3d describe the day they fall on
+func parseDay(value string, now time.Time) (int64, int64, error) {
+	t, _, err := parseFilterTime(value, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	day := startOfDay(convertDate(t))
+	return day.Unix(), day.AddDate(0, 0, 1).Unix(), nil
+}
+
+// dayCommand lists the tasks matching the filter given by args which are due on the day of the day flag
+func dayCommand(args []string, now time.Time) {
+	start, end, err := parseDay(calendarDay, now)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var tasks []Task
+	for _, t := range FilterTasks(parseFilterArgs(args), "id", "ASC") {
+		if t.Until >= start && t.Until < end {
+			tasks = append(tasks, t)
+		}
+	}
+	if machineOutput() {
+		if tasks == nil {
+			tasks = []Task{}
+		}
+		renderJSON(tasks)
+		return
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Until < tasks[j].Until
+	})
+	day := convertDate(start)
+	withPager(!noPager, func() {
+		RenderAgenda([]AgendaGroup{{Name: day.Format("Monday, Jan 2 2006"), Date: day.Format("2006-01-02"), Tasks: tasks}})
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseMonth(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"2027-02", time.Date(2027, 2, 1, 0, 0, 0, 0, time.Local), true},
+		{"3", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), true},
+		{"dec", time.Date(2026, 12, 1, 0, 0, 0, 0, time.Local), true},
+		{"November", time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local), true},
+		{"13", time.Time{}, false},
+		{"cat:work", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseMonth(tt.value, now)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseMonth(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func Test_parseDay(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	day := func(d int) int64 {
+		return time.Date(2026, 10, d, 0, 0, 0, 0, time.Local).Unix()
+	}
+	tests := []struct {
+		value string
+		day   int
+	}{
+		{"today", 21},
+		{"now", 21},
+		{"tomorrow", 22},
+		{"2026-10-25", 25},
+		{"3d", 24},
+		{"20h", 22},
+	}
+	for _, tt := range tests {
+		start, end, err := parseDay(tt.value, now)
+		if err != nil || start != day(tt.day) || end != day(tt.day+1) {
+			t.Errorf("parseDay(%q) = %d, %d, %v, want October %d", tt.value, start, end, err, tt.day)
+		}
+	}
+	if _, _, err := parseDay("soon", now); err == nil {
+		t.Error("Expected an invalid day to fail")
+	}
+}
+
+func Test_calendarDays(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	at := func(month time.Month, day int, hour int) int64 {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.Local).Unix()
+	}
+	tasks := []Task{
+		{Id: 1, Until: at(10, 20, 9)},
+		{Id: 2, Until: at(10, 20, 12), Done: true},
+		{Id: 3, Until: at(10, 21, 8), Done: true},
+		{Id: 4, Until: at(10, 21, 15)},
+		{Id: 5, Until: at(11, 1, 9)},
+		{Id: 6},
+	}
+
+	days := calendarDays(tasks, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), now)
+	if len(days) != 31 {
+		t.Fatalf("Got %d days, want 31", len(days))
+	}
+	want := map[int]CalendarDay{
+		20: {Date: "2026-10-20", Due: 2, Done: 1, Overdue: 1},
+		21: {Date: "2026-10-21", Due: 2, Done: 1},
+	}
+	for i, d := range days {
+		w, ok := want[i+1]
+		if !ok {
+			w = CalendarDay{Date: time.Date(2026, 10, i+1, 0, 0, 0, 0, time.Local).Format("2006-01-02")}
+		}
+		if !reflect.DeepEqual(d, w) {
+			t.Errorf("Got %+v, want %+v", d, w)
+		}
+	}
+}
+
+func TestRenderCalendar(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	month := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	days := calendarDays([]Task{{Id: 1, Until: time.Date(2026, 10, 2, 9, 0, 0, 0, time.Local).Unix()}}, month, month)
+	RenderCalendar(days, month, time.Date(2026, 10, 5, 9, 0, 0, 0, time.Local))
+
+	lines := strings.Split(buf.String(), "\n")
+	want := []string{
+		"",
+		"               October 2026",
+		" Mo    Tu    We    Th    Fr    Sa    Su",
+		"                    1     2 1   3     4",
+		"  5     6     7     8     9    10    11",
+	}
+	for i := range want {
+		if got := strings.TrimRight(lines[i], " "); got != want[i] {
+			t.Errorf("Got line %q, want %q", got, want[i])
+		}
+	}
+	if got := strings.TrimRight(lines[7], " "); got != " 26    27    28    29    30    31" {
+		t.Errorf("Got the last week %q", got)
+	}
+}
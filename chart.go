@@ -0,0 +1,313 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// heatmapWeeks is the amount of weeks the heatmap shows if the terminal is wide enough
+	heatmapWeeks = 53
+	// burndownDays is the amount of days a burndown shows if the terminal is wide enough
+	burndownDays = 42
+	// burndownHeight is the amount of lines of a burndown chart
+	burndownHeight = 8
+)
+
+// heatmapShades are the characters of the heatmap from no to the most completed tasks
+var heatmapShades = []string{"·", "░", "▒", "▓", "█"}
+
+// bars are the characters of the burndown chart filled by one to eight eighths
+var bars = []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// HeatmapDay is the amount of tasks completed on a day of the heatmap
+type HeatmapDay struct {
+	Date      string `json:"date"`
+	Completed int    `json:"completed"`
+}
+
+// Burndown is the amount of open tasks of a category at the end of every day starting with Start
+type Burndown struct {
+	Category string `json:"category"`
+	Start    string `json:"start"`
+	Open     []int  `json:"open"`
+	// Projected is the day all tasks are done if the pace stays the same,
+	// nil if there are no open tasks or the amount of open tasks does not go down
+	Projected *string `json:"projected"`
+}
+
+// completionsPerDay counts the tasks completed per day given as 2006-01-02
+func completionsPerDay(tasks []Task) map[string]int {
+	days := make(map[string]int)
+	for _, t := range tasks {
+		if t.Done && t.Completed != 0 {
+			days[convertDate(t.Completed).Format("2006-01-02")]++
+		}
+	}
+	return days
+}
+
+// heatmapDays returns the days of the weeks up to the one of now, starting on a Monday.
+// Days after today are left out
+func heatmapDays(tasks []Task, now time.Time, weeks int) []HeatmapDay {
+	counts := completionsPerDay(tasks)
+	first := periodStart(now, "week").AddDate(0, 0, -7*(weeks-1))
+	var days []HeatmapDay
+	for d := first; !d.After(now); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		days = append(days, HeatmapDay{Date: date, Completed: counts[date]})
+	}
+	return days
+}
+
+// heatmapLevel returns the shade of n completed tasks if the most on a day are max
+func heatmapLevel(n int, max int) int {
+	if n <= 0 || max <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(n*(len(heatmapShades)-1)) / float64(max)))
+}
+
+// RenderHeatmap renders the days as a grid with a column per week and a row per weekday,
+// the more tasks got completed on a day the darker its shade
+func RenderHeatmap(days []HeatmapDay) {
+	if len(days) == 0 {
+		return
+	}
+	first, _ := time.ParseInLocation("2006-01-02", days[0].Date, time.Local)
+	weeks := (len(days) + 6) / 7
+
+	max, total := 0, 0
+	for _, d := range days {
+		total += d.Completed
+		if d.Completed > max {
+			max = d.Completed
+		}
+	}
+
+	// the month names are written above the first week starting in the month if there is space
+	months := []byte(strings.Repeat(" ", weeks+6))
+	for w, end := 0, 0; w < weeks; w++ {
+		monday := first.AddDate(0, 0, 7*w)
+		if w > 0 && monday.AddDate(0, 0, -7).Month() == monday.Month() || w+3 < end || w > 0 && w+3 > weeks {
+			continue
+		}
+		copy(months[w+3:], monday.Format("Jan"))
+		end = w + 7
+	}
+	fmt.Fprintf(out, "\n%s\n", strings.TrimRight(string(months), " "))
+
+	labels := []string{"Mo", "", "We", "", "Fr", "", "Su"}
+	for weekday := 0; weekday < 7; weekday++ {
+		line := fmt.Sprintf("%-3s", labels[weekday])
+		for w := 0; w < weeks; w++ {
+			i := 7*w + weekday
+			if i >= len(days) {
+				break
+			}
+			level := heatmapLevel(days[i].Completed, max)
+			style := theme.Done
+			if level == 0 {
+				style = theme.Muted
+			}
+			line += paint(style, heatmapShades[level])
+		}
+		fmt.Fprintln(out, line)
+	}
+
+	fmt.Fprintf(out, "\n%d tasks completed, less %s more\n\n", total, strings.Join(heatmapShades, " "))
+}
+
+// openPerDay counts the tasks which are open at the end of every day from start until now.
+// Tasks finished before completion times got recorded are never counted
+func openPerDay(tasks []Task, start time.Time, now time.Time) []int {
+	var open []int
+	for d := start; !d.After(now); d = d.AddDate(0, 0, 1) {
+		end := d.AddDate(0, 0, 1).Unix()
+		if end > now.Unix() {
+			end = now.Unix()
+		}
+		n := 0
+		for _, t := range tasks {
+			if t.Created > end || t.Done && (t.Completed == 0 || t.Completed <= end) {
+				continue
+			}
+			n++
+		}
+		open = append(open, n)
+	}
+	return open
+}
+
+// projectCompletion returns the day the open tasks reach zero following the linear trend of open,
+// the last value of open is the amount of today
+func projectCompletion(open []int, now time.Time) (time.Time, bool) {
+	n := float64(len(open))
+	if len(open) < 2 || open[len(open)-1] == 0 {
+		return time.Time{}, false
+	}
+
+	// slope of the least squares line through the days
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range open {
+		x, y := float64(i), float64(v)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	if slope >= 0 {
+		return time.Time{}, false
+	}
+	days := int(math.Ceil(float64(open[len(open)-1]) / -slope))
+	return startOfDay(now).AddDate(0, 0, days), true
+}
+
+// burndowns returns the burndown of every category of the tasks with tasks open during the days until now
+func burndowns(tasks []Task, now time.Time, days int) []Burndown {
+	categories := make(map[string][]Task)
+	for _, t := range tasks {
+		categories[t.CategoryName] = append(categories[t.CategoryName], t)
+	}
+
+	start := startOfDay(now).AddDate(0, 0, 1-days)
+	var result []Burndown
+	for name, categoryTasks := range categories {
+		b := Burndown{Category: name, Start: start.Format("2006-01-02"), Open: openPerDay(categoryTasks, start, now)}
+		empty := true
+		for _, n := range b.Open {
+			if n > 0 {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		if day, ok := projectCompletion(b.Open, now); ok {
+			projected := day.Format("2006-01-02")
+			b.Projected = &projected
+		}
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Category < result[j].Category
+	})
+	return result
+}
+
+// barLines draws the values as vertical bars of the given height, the highest value fills all lines
+func barLines(values []int, height int) []string {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	lines := make([]string, height)
+	for row := 0; row < height; row++ {
+		var line strings.Builder
+		for _, v := range values {
+			// eighths of the line filled by the bar, counted from the bottom line
+			eighths := 0
+			if max > 0 {
+				eighths = int(math.Round(float64(v*height*8)/float64(max))) - (height-1-row)*8
+			}
+			switch {
+			case eighths >= 8:
+				line.WriteString(bars[7])
+			case eighths > 0:
+				line.WriteString(bars[eighths-1])
+			default:
+				line.WriteString(" ")
+			}
+		}
+		lines[row] = line.String()
+	}
+	return lines
+}
+
+// RenderBurndowns renders a chart per category with the open tasks, the day they are projected to be done
+// and the axis labelled with the most open tasks and the first and last day
+func RenderBurndowns(charts []Burndown) {
+	fmt.Fprintln(out)
+	for _, b := range charts {
+		open := b.Open[len(b.Open)-1]
+		projection := "no projected completion"
+		switch {
+		case open == 0:
+			projection = "all done"
+		case b.Projected != nil:
+			day, _ := time.ParseInLocation("2006-01-02", *b.Projected, time.Local)
+			projection = "done by " + day.Format("Jan 2 2006")
+		}
+		fmt.Fprintf(out, "%s - %d open, %s\n", paint(theme.Category, b.Category), open, paint(theme.Muted, projection))
+
+		max := 0
+		for _, v := range b.Open {
+			if v > max {
+				max = v
+			}
+		}
+		labelWidth := len(fmt.Sprint(max))
+		for i, line := range barLines(b.Open, burndownHeight) {
+			label := ""
+			switch i {
+			case 0:
+				label = fmt.Sprint(max)
+			case burndownHeight - 1:
+				label = "0"
+			}
+			fmt.Fprintf(out, "%*s │%s\n", labelWidth, label, paint(theme.Open, line))
+		}
+
+		start, _ := time.ParseInLocation("2006-01-02", b.Start, time.Local)
+		first, last := start.Format("Jan 2"), start.AddDate(0, 0, len(b.Open)-1).Format("Jan 2")
+		gap := len(b.Open) - len(first) - len(last)
+		if gap < 1 {
+			gap = 1
+		}
+		fmt.Fprintf(out, "%*s └%s\n", labelWidth, "", strings.Repeat("─", len(b.Open)))
+		fmt.Fprintf(out, "%*s  %s%s%s\n\n", labelWidth, "", first, strings.Repeat(" ", gap), last)
+	}
+}
+
+// chartLength returns how many days or weeks fit next to a label of labelWidth cells, at most max
+func chartLength(max int, labelWidth int) int {
+	if termWidth > 0 && termWidth-labelWidth < max {
+		if termWidth-labelWidth < 7 {
+			return 7
+		}
+		return termWidth - labelWidth
+	}
+	return max
+}
+
+// heatmapCommand renders the completions of the last year of the tasks matching the filter given by args
+func heatmapCommand(args []string) {
+	days := heatmapDays(FilterTasks(parseFilterArgs(args), "id", "ASC"), time.Now(), chartLength(heatmapWeeks, 3))
+	if machineOutput() {
+		renderJSON(days)
+		return
+	}
+	RenderHeatmap(days)
+}
+
+// burndownCommand renders the burndown of every category of the tasks matching the filter given by args
+func burndownCommand(args []string) {
+	charts := burndowns(FilterTasks(parseFilterArgs(args), "id", "ASC"), time.Now(), chartLength(burndownDays, 6))
+	if machineOutput() {
+		if charts == nil {
+			charts = []Burndown{}
+		}
+		renderJSON(charts)
+		return
+	}
+	withPager(!noPager, func() {
+		RenderBurndowns(charts)
+	})
+}
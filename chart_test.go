@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_heatmapDays(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	tasks := []Task{
+		{Id: 1, Done: true, Completed: time.Date(2026, 10, 20, 9, 0, 0, 0, time.Local).Unix()},
+		{Id: 2, Done: true, Completed: time.Date(2026, 10, 20, 18, 0, 0, 0, time.Local).Unix()},
+		{Id: 3, Done: true, Completed: time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local).Unix()},
+		{Id: 4, Done: true},
+		{Id: 5},
+	}
+
+	got := heatmapDays(tasks, now, 2)
+	want := []HeatmapDay{
+		{"2026-10-12", 1}, {"2026-10-13", 0}, {"2026-10-14", 0}, {"2026-10-15", 0}, {"2026-10-16", 0}, {"2026-10-17", 0}, {"2026-10-18", 0},
+		{"2026-10-19", 0}, {"2026-10-20", 2}, {"2026-10-21", 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func Test_heatmapLevel(t *testing.T) {
+	tests := []struct {
+		n, max, want int
+	}{
+		{0, 8, 0},
+		{1, 8, 1},
+		{2, 8, 1},
+		{3, 8, 2},
+		{8, 8, 4},
+		{1, 1, 4},
+	}
+	for _, tt := range tests {
+		if got := heatmapLevel(tt.n, tt.max); got != tt.want {
+			t.Errorf("heatmapLevel(%d, %d) = %d, want %d", tt.n, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestRenderHeatmap(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	RenderHeatmap([]HeatmapDay{
+		{"2026-09-28", 4}, {"2026-09-29", 0}, {"2026-09-30", 0}, {"2026-10-01", 0}, {"2026-10-02", 0}, {"2026-10-03", 0}, {"2026-10-04", 0},
+		{"2026-10-05", 0}, {"2026-10-06", 1}, {"2026-10-07", 0},
+	})
+
+	want := "\n   Sep\nMo █·\n   ·░\nWe ··\n   ·\nFr ·\n   ·\nSu ·\n\n5 tasks completed, less · ░ ▒ ▓ █ more\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func Test_openPerDay(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	at := func(day int, hour int) int64 {
+		return time.Date(2026, 10, 21+day, hour, 0, 0, 0, time.Local).Unix()
+	}
+	tasks := []Task{
+		{Id: 1, Created: at(-5, 9)},
+		{Id: 2, Created: at(-4, 9), Done: true, Completed: at(-2, 9)},
+		{Id: 3, Created: at(-2, 9), Done: true, Completed: at(0, 9)},
+		{Id: 4, Created: at(-4, 9), Done: true},
+		{Id: 5, Created: at(0, 11)},
+	}
+
+	got := openPerDay(tasks, startOfDay(now).AddDate(0, 0, -4), now)
+	if want := []int{2, 2, 2, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, want %v", got, want)
+	}
+}
+
+func Test_projectCompletion(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		open []int
+		want string
+		ok   bool
+	}{
+		{[]int{10, 8, 6, 4}, "2026-10-23", true},
+		{[]int{5, 5, 5}, "", false},
+		{[]int{1, 2, 3}, "", false},
+		{[]int{3, 0}, "", false},
+		{[]int{4}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := projectCompletion(tt.open, now)
+		if ok != tt.ok || ok && got.Format("2006-01-02") != tt.want {
+			t.Errorf("projectCompletion(%v) = %v, %v, want %s, %v", tt.open, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func Test_barLines(t *testing.T) {
+	got := barLines([]int{0, 1, 2, 4}, 2)
+	want := []string{"   █", " ▄██"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestRenderBurndowns(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	projected := "2026-10-23"
+	RenderBurndowns([]Burndown{
+		{Category: "home", Start: "2026-10-08", Open: []int{16, 14, 12, 10, 8, 6, 4, 4, 4, 4, 4, 4, 4, 4}, Projected: &projected},
+		{Category: "work", Start: "2026-10-08", Open: []int{2, 1, 0}},
+	})
+
+	got := buf.String()
+	for _, want := range []string{"home - 4 open, done by Oct 23 2026\n", "16 │█", " 0 │██████████████\n", "   └──────────────\n", "    Oct 8   Oct 21\n", "work - 0 open, all done\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+}
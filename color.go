@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// The values of the color flag
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// colors is used for all coloured output, it writes plain text
+// if colours are disabled by setColors
+var colors = aurora.NewAurora(true)
+
+// colorsEnabled reports whether the output contains colours
+var colorsEnabled = true
+
+// useColors decides whether the output should be coloured.
+// always and never override everything, auto only colours the output
+// of a terminal if the NO_COLOR environment variable is not set
+func useColors(mode string, noColor bool, terminal bool) (bool, error) {
+	switch mode {
+	case colorAlways:
+		return true, nil
+	case colorNever:
+		return false, nil
+	case colorAuto, "":
+		return !noColor && terminal, nil
+	}
+	return false, fmt.Errorf("invalid color %q, expected auto, always or never", mode)
+}
+
+// setColors enables or disables the colours of all renderers
+func setColors(enabled bool) {
+	colorsEnabled = enabled
+	colors = aurora.NewAurora(enabled)
+}
+
+// noColorSet reports whether the NO_COLOR environment variable is set to a non-empty value,
+// see https://no-color.org
+func noColorSet() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
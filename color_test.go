@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_useColors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		noColor  bool
+		terminal bool
+		want     bool
+		wantErr  bool
+	}{
+		{"auto terminal", colorAuto, false, true, true, false},
+		{"auto pipe", colorAuto, false, false, false, false},
+		{"auto NO_COLOR", colorAuto, true, true, false, false},
+		{"always pipe", colorAlways, false, false, true, false},
+		{"always NO_COLOR", colorAlways, true, false, true, false},
+		{"never terminal", colorNever, false, true, false, false},
+		{"invalid", "sometimes", false, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := useColors(tt.mode, tt.noColor, tt.terminal)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("useColors() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("useColors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetColorsDisabled(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	TaskDone([]string{"2"})
+
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	task := Task{Id: 1, Description: "Clean Room", Until: time.Now().Add(10*24*time.Hour + time.Hour).Unix()}
+	if got := strings.Join(task.StringArray(), "|"); got != "⨉|1|Clean Room|10w|" {
+		t.Errorf("Got %q, expected the task without colours", got)
+	}
+
+	RenderAligned("id", "ASC", nil, Page{})
+	RenderTableTasks("id", "ASC", nil, Page{}, defaultTableColumns)
+	got := buf.String()
+	if strings.Contains(got, "\x1b") {
+		t.Errorf("Expected no colour codes in %q", got)
+	}
+	if !strings.Contains(got, "     ✓  2 Add Tests\n") {
+		t.Errorf("Expected the check box to stay aligned in %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// orders of the categories in the aligned view
+const (
+	categoryOrderAlpha  = "alpha"
+	categoryOrderUrgent = "urgent"
+	categoryOrderOpen   = "open"
+	categoryOrderPinned = "pinned"
+)
+
+// Config represents the settings of the user,
+// saved as config.json next to the database
+type Config struct {
+	// CategoryOrder is the order of the categories in the aligned view,
+	// one of alpha, urgent, open or pinned
+	CategoryOrder string `json:"categoryOrder"`
+	// PinnedCategories are shown first and in the given order
+	// if the CategoryOrder is pinned
+	PinnedCategories []string `json:"pinnedCategories"`
+	// CollapseDone only shows the name of categories without open tasks
+	CollapseDone bool `json:"collapseDone"`
+	// Urgency weights the factors of the urgency of a task
+	Urgency UrgencyCoefficients `json:"urgency"`
+	// Compact uses less space for narrow terminals
+	Compact bool `json:"compact"`
+	// Truncate cuts long descriptions off instead of wrapping them
+	Truncate bool `json:"truncate"`
+	// AgendaHorizon is the amount of days the agenda shows one by one,
+	// tasks due later are shown under Later
+	AgendaHorizon int `json:"agendaHorizon"`
+	// Templates are named templates which can be used with -template NAME
+	Templates map[string]string `json:"templates"`
+	// Theme is the name of the bundled or defined theme of the output
+	Theme string `json:"theme"`
+	// Themes are the themes defined by the user, they can be based on other themes
+	Themes map[string]Theme `json:"themes"`
+}
+
+var config = defaultConfig()
+
+// configPath is the path of the config file, next to the database
+var configPath string
+
+// defaultConfig returns the settings used if there is no config file
+func defaultConfig() Config {
+	return Config{CategoryOrder: categoryOrderAlpha, Urgency: defaultUrgencyCoefficients(), Theme: themeDark, AgendaHorizon: defaultAgendaHorizon}
+}
+
+// loadConfig reads the config file at path,
+// missing settings and a missing file fall back to the defaults
+func loadConfig(path string) (Config, error) {
+	c := defaultConfig()
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err = json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+	return c, c.validate()
+}
+
+// validate checks that the settings have valid values
+func (c *Config) validate() error {
+	if c.AgendaHorizon < 1 {
+		return fmt.Errorf("invalid agenda horizon %d, expected at least 1 day", c.AgendaHorizon)
+	}
+	switch c.CategoryOrder {
+	case categoryOrderAlpha, categoryOrderUrgent, categoryOrderOpen, categoryOrderPinned:
+		return nil
+	default:
+		return fmt.Errorf("unknown category order %q, expected one of %s, %s, %s or %s", c.CategoryOrder,
+			categoryOrderAlpha, categoryOrderUrgent, categoryOrderOpen, categoryOrderPinned)
+	}
+}
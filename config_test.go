@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_loadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name      string
+		content   string
+		wantOrder string
+		wantErr   bool
+	}{
+		{"missing file", "", categoryOrderAlpha, false},
+		{"pinned", `{"categoryOrder": "pinned", "pinnedCategories": ["work"]}`, categoryOrderPinned, false},
+		{"defaults", `{"collapseDone": true}`, categoryOrderAlpha, false},
+		{"unknown order", `{"categoryOrder": "random"}`, "random", true},
+		{"invalid json", `{"categoryOrder": `, categoryOrderAlpha, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if tt.content != "" {
+				if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := loadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.CategoryOrder != tt.wantOrder {
+				t.Errorf("loadConfig() CategoryOrder = %v, want %v", got.CategoryOrder, tt.wantOrder)
+			}
+		})
+	}
+}
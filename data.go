@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,15 +12,28 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
-	. "github.com/logrusorgru/aurora"
+	"github.com/mattn/go-sqlite3"
 )
 
 var ctx = context.Background()
 var defaultCategoryID int64 = 1
-var db *sql.DB
+var db queryer
+
+// conn is the connection to the database, db is either it or the transaction currently running
+var conn *sql.DB
+
+// queryer runs queries, it is implemented by the database and transactions
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
 
 // Task represents a task of the user
 type Task struct {
@@ -29,46 +44,57 @@ type Task struct {
 	Done         bool
 	CategoryId   int64
 	CategoryName string
+	Tags         []string
+	Notes        string
+	Priority     int
+	Depends      []int64
+	// Completed is the time the task got marked as done, 0 if it is open or got finished before it was recorded
+	Completed int64
+	// Uuid identifies the task in other tools like Taskwarrior, it is assigned when the task is created
+	Uuid string
+	// Blocked is set if the task depends on open tasks
+	Blocked bool
 }
 
 // Category represents a category which tasks can be assigned to
 type Category struct {
-	Id   int64
-	Name string
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
 }
 
 func (task *Task) getCheckBox() string {
 
-	checkBox := ""
 	if task.Done {
-		checkBox = Green("\u2713").String()
-	} else {
-		checkBox = Red("\u2A09").String()
+		return paint(theme.Done, theme.DoneGlyph)
 	}
-
-	return checkBox
+	return paint(theme.Open, theme.OpenGlyph)
 }
 
 func (task *Task) StringArray() []string {
 
 	desc := task.Description
-	id := Bold(task.Id).String()
+	id := paint(theme.Id, task.Id)
 	untilString := timeUntil(task.Until)
-	done := task.Done
 	catName := task.CategoryName
-	var checkBox string
-
-	if done {
-		checkBox = Green("\u2713").String()
-	} else {
-		checkBox = Red("\u2A09").String()
-	}
+	checkBox := task.getCheckBox()
 
 	return []string{checkBox, id, desc, untilString, catName}
 
 }
 
-func SaveTask(categoryName string, description string, day int64, hour int64) *Task {
+// SaveTask saves a new task with the given category, description, due time and tags,
+// its id is 0 if a task with the description already exists
+func SaveTask(categoryName string, description string, day int64, hour int64, tags ...string) (*Task, error) {
+	task := newTask(categoryName, description, day, hour)
+	task.Tags = tags
+	err := insertTask(&task)
+
+	return &task, err
+}
+
+// newTask creates a task with the given category, description and due time
+// without saving it
+func newTask(categoryName string, description string, day int64, hour int64) Task {
 	now := time.Now().Unix()
 
 	if categoryName != "" {
@@ -91,9 +117,7 @@ func SaveTask(categoryName string, description string, day int64, hour int64) *T
 		task.Until = until
 	}
 
-	insertTask(&task)
-
-	return &task
+	return task
 }
 
 func (c *Category) StringArray() []string {
@@ -105,6 +129,25 @@ func (c *Category) StringArray() []string {
 // one for running the application and one for testing
 func setDB(database *sql.DB) {
 	db = database
+	conn = database
+}
+
+// inTransaction runs fn with all queries in one transaction,
+// which is rolled back if fn fails and committed otherwise
+func inTransaction(fn func() error) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	previous := db
+	db = tx
+	err = fn()
+	db = previous
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 // CreateTableTaskCategory creates a task table in the database if it does not exist
@@ -133,19 +176,96 @@ func CreateTableTaskCategory() {
 
 	_, err = db.Exec(defaultCategory)
 	checkErrorQueries(err, defaultCategory)
+
+	addColumn("tasks", "tags", "text not null DEFAULT ''")
+	addColumn("tasks", "notes", "text not null DEFAULT ''")
+	addColumn("tasks", "priority", "integer not null DEFAULT 0")
+	addColumn("tasks", "depends", "text not null DEFAULT ''")
+	addColumn("tasks", "completed", "integer not null DEFAULT 0")
+	addColumn("tasks", "uuid", "text not null DEFAULT ''")
+	assignUuids()
+
+	createSearchIndex()
+}
+
+// assignUuids gives the tasks created before tasks got a uuid one
+func assignUuids() {
+	sqlStmt := `SELECT id FROM tasks WHERE uuid='';`
+	rows, err := db.Query(sqlStmt)
+	if err != nil {
+		checkErrorQueries(err, sqlStmt)
+		return
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			log.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	for _, id := range ids {
+		SetUuid(id, newUuid())
+	}
+}
+
+// newUuid returns a random version 4 UUID
+func newUuid() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// addColumn adds the column to the table if it does not exist yet.
+// Used to upgrade databases which were created by an older version
+func addColumn(table string, column string, definition string) {
+	sqlStmt := fmt.Sprintf("PRAGMA table_info(%s);", table)
+	rows, err := db.Query(sqlStmt)
+	if err != nil {
+		checkErrorQueries(err, sqlStmt)
+		return
+	}
+
+	exists := false
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, kind       string
+			defaultValue     sql.NullString
+		)
+		err = rows.Scan(&cid, &name, &kind, &notNull, &defaultValue, &pk)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+
+	if !exists {
+		sqlStmt = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)
+		_, err = db.Exec(sqlStmt)
+		checkErrorQueries(err, sqlStmt)
+	}
 }
 
 // GetOrCreateCategory creates a new category if it is not present in the database
 // and then returns it or the already existing one
 func GetOrCreateCategory(name string) (id int64, err error) {
 	name = strings.ToLower(name)
-	sqlStmt := fmt.Sprintf(`SELECT id FROM categories WHERE name="%s";`, name)
-	row := db.QueryRow(sqlStmt)
+	row := db.QueryRow(`SELECT id FROM categories WHERE name=$1;`, name)
 
 	switch err := row.Scan(&id); err {
 	case sql.ErrNoRows:
-		sqlStmt = fmt.Sprintf(`INSERT INTO "categories" (name) VALUES('%s');`, name)
-		res, err := db.Exec(sqlStmt)
+		// imported names can contain quotes
+		sqlStmt := `INSERT INTO "categories" (name) VALUES($1);`
+		res, err := db.Exec(sqlStmt, name)
 		checkErrorQueries(err, sqlStmt)
 		id, err = res.LastInsertId()
 		return id, err
@@ -156,37 +276,75 @@ func GetOrCreateCategory(name string) (id int64, err error) {
 	}
 }
 
-// NewTasks inserts a new task in the database
-func insertTask(t *Task) *Task {
+// insertTask inserts a new task in the database, its id is 0 if a task with its description already exists
+func insertTask(t *Task) error {
 
 	if t.CategoryId <= 0 {
 		t.CategoryId = defaultCategoryID
 	}
 
-	sqlStmt := "INSERT OR IGNORE INTO tasks (description, created, until, category_id) VALUES ($1, $2, $3, $4)"
-	res, err := db.Exec(sqlStmt, t.Description, t.Created, t.Until, t.CategoryId)
-	checkErrorQueries(err, sqlStmt)
+	if t.Uuid == "" {
+		t.Uuid = newUuid()
+	}
+
+	sqlStmt := "INSERT OR IGNORE INTO tasks (description, created, until, category_id, tags, notes, priority, depends, uuid) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+	res, err := db.Exec(sqlStmt, t.Description, t.Created, t.Until, t.CategoryId, strings.Join(t.Tags, " "), t.Notes, t.Priority, joinIds(t.Depends), t.Uuid)
+	if err != nil {
+		return err
+	}
+
+	// a task with the same description already exists if nothing got inserted
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		t.Id = 0
+		return nil
+	}
 
 	// Update the Id of Task
 	id, err := res.LastInsertId()
-
 	if err != nil {
-		panic(err)
+		return err
 	}
 	t.Id = id
 
-	return t
+	return nil
 }
 
 // AllTasks returns all tasks in the database.
 // You have to pass an orderBy and sorted argument for the query
 func AllTasks(orderBy string, sorted string) []Task {
+	return FilterTasks(nil, orderBy, sorted)
+}
 
-	sqlStmt := fmt.Sprintf("SELECT t.id, t.description, t.created, t.until, t.done, c.name FROM tasks as t INNER JOIN categories As c ON (t.category_id=c.id) ORDER BY t.%s %s;", orderBy, sorted)
+// FilterTasks returns all tasks in the database matching the filter.
+// A nil filter matches every task
+func FilterTasks(filter *Filter, orderBy string, sorted string) []Task {
+	return FilterTasksPage(filter, orderBy, sorted, 0, 0)
+}
 
-	rows, err := db.QueryContext(ctx, sqlStmt)
+// FilterTasksPage returns at most limit tasks matching the filter,
+// skipping the first offset tasks. A limit of 0 returns all tasks.
+// Ordering by urgency lists the most urgent tasks first
+func FilterTasksPage(filter *Filter, orderBy string, sorted string, limit int, offset int) []Task {
 
-	defer rows.Close()
+	byUrgency := orderBy == urgencyOrder
+	sqlOrderBy, sqlSorted := orderBy, sorted
+	if byUrgency {
+		// the urgency is not a column, so we have to sort and page after loading all tasks
+		sqlOrderBy, sqlSorted = "id", "ASC"
+	}
+
+	where, args := filter.Where()
+	sqlLimit, sqlOffset := limit, offset
+	if sqlLimit <= 0 || byUrgency {
+		sqlLimit, sqlOffset = -1, 0
+	}
+	sqlStmt := fmt.Sprintf("SELECT %s FROM tasks as t INNER JOIN categories As c ON (t.category_id=c.id) WHERE %s ORDER BY t.%s %s LIMIT %d OFFSET %d;", taskColumns, where, sqlOrderBy, sqlSorted, sqlLimit, sqlOffset)
+
+	rows, err := db.QueryContext(ctx, sqlStmt, args...)
 	tasks := make([]Task, 0)
 
 	switch err {
@@ -195,18 +353,10 @@ func AllTasks(orderBy string, sorted string) []Task {
 		fmt.Println("No Tasks in the Database yet, add some")
 
 	case nil:
+		defer rows.Close()
 
 		for rows.Next() {
-			var task Task
-			err = rows.Scan(
-				&task.Id,
-				&task.Description,
-				&task.Created,
-				&task.Until,
-				&task.Done,
-				&task.CategoryName,
-			)
-
+			task, err := scanTask(rows)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -218,10 +368,121 @@ func AllTasks(orderBy string, sorted string) []Task {
 
 	}
 
+	markBlocked(tasks)
+
+	if byUrgency {
+		sortByUrgency(tasks, config.Urgency, time.Now())
+		if sorted == "DESC" {
+			for i, j := 0, len(tasks)-1; i < j; i, j = i+1, j-1 {
+				tasks[i], tasks[j] = tasks[j], tasks[i]
+			}
+		}
+		start, end := Page{limit, offset}.slice(len(tasks))
+		tasks = tasks[start:end]
+	}
+
 	return tasks
 
 }
 
+// taskColumns are the columns of a task as read by scanTask,
+// the tasks table is called t and the categories table c
+const taskColumns = "t.id, t.description, t.created, t.until, t.done, t.category_id, c.name, t.tags, t.notes, t.priority, t.depends, t.completed, t.uuid"
+
+// scanTask scans a row starting with the taskColumns,
+// the values of additional columns get scanned into extra
+func scanTask(rows *sql.Rows, extra ...interface{}) (Task, error) {
+	var task Task
+	var tags, depends string
+	dest := []interface{}{
+		&task.Id,
+		&task.Description,
+		&task.Created,
+		&task.Until,
+		&task.Done,
+		&task.CategoryId,
+		&task.CategoryName,
+		&tags,
+		&task.Notes,
+		&task.Priority,
+		&depends,
+		&task.Completed,
+		&task.Uuid,
+	}
+	err := rows.Scan(append(dest, extra...)...)
+	task.Tags = strings.Fields(tags)
+	task.Depends = splitIds(depends)
+	return task, err
+}
+
+// markBlocked sets Blocked for all tasks which depend on open tasks
+func markBlocked(tasks []Task) {
+	var depends []string
+	for _, t := range tasks {
+		for _, id := range t.Depends {
+			depends = append(depends, strconv.FormatInt(id, 10))
+		}
+	}
+	if len(depends) == 0 {
+		return
+	}
+
+	sqlStmt := fmt.Sprintf("SELECT id FROM tasks WHERE NOT done AND id in (%s)", strings.Join(depends, ", "))
+	rows, err := db.QueryContext(ctx, sqlStmt)
+	if err != nil {
+		checkErrorQueries(err, sqlStmt)
+		return
+	}
+	defer rows.Close()
+
+	open := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			log.Fatal(err)
+		}
+		open[id] = true
+	}
+
+	for i := range tasks {
+		for _, id := range tasks[i].Depends {
+			if open[id] {
+				tasks[i].Blocked = true
+			}
+		}
+	}
+}
+
+// joinIds converts the ids into the space separated form stored in the database
+func joinIds(ids []int64) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(s, " ")
+}
+
+// splitIds parses ids separated by spaces or commas, invalid ids are skipped
+func splitIds(s string) []int64 {
+	var ids []int64
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if id, err := strconv.ParseInt(field, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// CountTasks returns the amount of tasks matching the filter
+// and how many of them are done
+func CountTasks(filter *Filter) (total int, done int) {
+	where, args := filter.Where()
+	sqlStmt := fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(t.done), 0) FROM tasks as t INNER JOIN categories As c ON (t.category_id=c.id) WHERE %s;", where)
+	err := db.QueryRowContext(ctx, sqlStmt, args...).Scan(&total, &done)
+	checkErrorQueries(err, sqlStmt)
+	return total, done
+}
+
 // AllCategories returns all categories present in the database
 func AllCategories() []Category {
 
@@ -280,13 +541,76 @@ func DeleteTasksById(ids idFlags) {
 	checkErrorQueries(err, sqlStmt)
 }
 
-// TaskDone marks tasks as done in the database
+// AddTags adds the tags to all tasks given by id
+func AddTags(ids idFlags, tags []string) {
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		sqlStmt := fmt.Sprintf(`UPDATE tasks SET tags=TRIM(tags || ' ' || $1) WHERE id in (%s) AND (' ' || tags || ' ') NOT LIKE $2 ESCAPE '\'`, ids.String())
+		_, err := db.Exec(sqlStmt, tag, "% "+escapeLike(tag)+" %")
+		checkErrorQueries(err, sqlStmt)
+	}
+}
+
+// SetPriority sets the priority of all tasks given by id
+func SetPriority(ids idFlags, priority int) {
+	sqlStmt := fmt.Sprintf(`UPDATE tasks SET priority=$1 WHERE id in (%s)`, ids.String())
+	_, err := db.Exec(sqlStmt, priority)
+	checkErrorQueries(err, sqlStmt)
+}
+
+// SetDepends sets the tasks the tasks given by id depend on
+func SetDepends(ids idFlags, depends []int64) {
+	sqlStmt := fmt.Sprintf(`UPDATE tasks SET depends=$1 WHERE id in (%s)`, ids.String())
+	_, err := db.Exec(sqlStmt, joinIds(depends))
+	checkErrorQueries(err, sqlStmt)
+}
+
+// SetNotes replaces the notes of all tasks given by id
+func SetNotes(ids idFlags, notes string) {
+	sqlStmt := fmt.Sprintf(`UPDATE tasks SET notes=$1 WHERE id in (%s)`, ids.String())
+	_, err := db.Exec(sqlStmt, notes)
+	checkErrorQueries(err, sqlStmt)
+}
+
+// TaskDone marks tasks as done in the database and records when,
+// tasks which are already done keep their completion time
 func TaskDone(ids idFlags) {
-	sqlStmt := fmt.Sprintf("UPDATE tasks set done=TRUE WHERE id in (%s)", ids.String())
+	sqlStmt := fmt.Sprintf("UPDATE tasks set done=TRUE, completed=CASE WHEN done THEN completed ELSE $1 END WHERE id in (%s)", ids.String())
+	_, err := db.Exec(sqlStmt, time.Now().Unix())
+	checkErrorQueries(err, sqlStmt)
+}
+
+// TaskUndone marks finished tasks as open again
+func TaskUndone(ids idFlags) {
+	sqlStmt := fmt.Sprintf("UPDATE tasks set done=FALSE, completed=0 WHERE id in (%s)", ids.String())
 	_, err := db.Exec(sqlStmt)
 	checkErrorQueries(err, sqlStmt)
 }
 
+// SetUuid sets the uuid of the task given by id
+func SetUuid(id int64, uuid string) {
+	sqlStmt := `UPDATE tasks SET uuid=$1 WHERE id=$2`
+	_, err := db.Exec(sqlStmt, uuid, id)
+	checkErrorQueries(err, sqlStmt)
+}
+
+// SetDescription replaces the description of the task with the id,
+// it fails if another task has the description already
+func SetDescription(id int64, description string) error {
+	sqlStmt := `UPDATE tasks SET description=$1 WHERE id=$2`
+	if _, err := db.Exec(sqlStmt, description, id); err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return fmt.Errorf("a task with the description %q already exists", description)
+		}
+		return err
+	}
+	return nil
+}
+
 // getDbPath returns the path of the database given by name
 func getDbPath(dbName string) (dir string, db string, fullPath string, err error) {
 	//https://stackoverflow.com/questions/32163425/how-to-get-the-directory-of-the-package-the-file-is-in-not-the-current-working
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"regexp"
 	"testing"
 
 	. "github.com/logrusorgru/aurora"
@@ -37,6 +38,40 @@ func TestCreateTableTaskCategory(t *testing.T) {
 	}
 }
 
+func Test_newUuid(t *testing.T) {
+	uuid := newUuid()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid) {
+		t.Errorf("Got %q, expected a version 4 UUID", uuid)
+	}
+	if uuid == newUuid() {
+		t.Error("Expected different UUIDs")
+	}
+}
+
+func Test_assignUuids(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	tasks := AllTasks("id", "ASC")
+	for _, task := range tasks {
+		if task.Uuid == "" {
+			t.Errorf("Expected the new task %d to get a uuid", task.Id)
+		}
+	}
+
+	// tasks created before tasks had a uuid get one
+	if _, err := testDB.Exec("UPDATE tasks SET uuid='' WHERE id=2"); err != nil {
+		t.Fatal(err)
+	}
+	assignUuids()
+	migrated := AllTasks("id", "ASC")
+	if migrated[1].Uuid == "" || migrated[1].Uuid == tasks[1].Uuid {
+		t.Errorf("Got the uuid %q, expected a new one", migrated[1].Uuid)
+	}
+	if migrated[0].Uuid != tasks[0].Uuid || migrated[2].Uuid != tasks[2].Uuid {
+		t.Errorf("Got %v, expected the other uuids to stay the same", migrated)
+	}
+}
+
 func TestGetOrCreateCategory(t *testing.T) {
 	defer cleanDatabase()
 
@@ -124,6 +159,67 @@ func TestDeleteTasksById(t *testing.T) {
 	}
 }
 
+func TestTaskUndone(t *testing.T) {
+	defer cleanDatabase()
+
+	createThreeTasks()
+	TaskDone([]string{"1", "2"})
+	TaskUndone([]string{"2"})
+
+	_, done := CountTasks(nil)
+	if done != 1 {
+		t.Errorf("Got %d done tasks, expected %d", done, 1)
+	}
+
+	tasks := FilterTasks(&Filter{}, "id", "ASC")
+	if tasks[0].Completed == 0 || tasks[1].Completed != 0 {
+		t.Errorf("Got the completion times %d and %d, expected only task 1 to be completed", tasks[0].Completed, tasks[1].Completed)
+	}
+}
+
+func TestSetDescription(t *testing.T) {
+	defer cleanDatabase()
+
+	createThreeTasks()
+	if err := SetDescription(3, "Buy a present"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := FilterTasks(&Filter{}, "id", "ASC")
+	if tasks[2].Description != "Buy a present" || tasks[0].Description != "Clean Room" {
+		t.Errorf("Got %v, expected only the description of task 3 to change", tasks)
+	}
+	if err := SetDescription(2, "Clean Room"); err == nil || err.Error() != `a task with the description "Clean Room" already exists` {
+		t.Errorf("Got %v, expected the description of another task to be refused", err)
+	}
+}
+
+func Test_insertTask_duplicate(t *testing.T) {
+	defer cleanDatabase()
+
+	createThreeTasks()
+	task := newTask("work", "Clean Room", -1, -1)
+	if err := insertTask(&task); err != nil || task.Id != 0 {
+		t.Errorf("Got the id %d and %v, expected 0 for an existing description", task.Id, err)
+	}
+	if tasks := AllTasks("id", "ASC"); len(tasks) != 3 {
+		t.Errorf("Got %d tasks, want 3", len(tasks))
+	}
+
+	// a failing insert is an error instead of a duplicate
+	closed, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+	setDB(closed)
+	defer setDB(testDB)
+	task = newTask("", "Water plants", -1, -1)
+	if err := insertTask(&task); err == nil {
+		t.Error("Expected the insert into a closed database to fail")
+	}
+}
+
 func Test_getDbPath(t *testing.T) {
 	wantDir, _ := os.Getwd()
 	wantFullPath := wantDir + "/" + "todo.db"
@@ -207,6 +303,22 @@ func TestAllTasks(t *testing.T) {
 
 }
 
+func TestFilterTasksPage(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	tasks := FilterTasksPage(nil, "id", "ASC", 2, 1)
+	if len(tasks) != 2 || tasks[0].Id != 2 {
+		t.Errorf("Got %v, expected the tasks 2 and 3", tasks)
+	}
+
+	TaskDone([]string{"1"})
+	total, done := CountTasks(nil)
+	if total != 3 || done != 1 {
+		t.Errorf("Got %d total and %d done, expected 3 and 1", total, done)
+	}
+}
+
 func TestTask_getCheckBox(t *testing.T) {
 	type fields struct {
 		Id           int64
@@ -280,10 +392,14 @@ func cleanDatabase() {
 	clearTasks := "DROP table tasks"
 	clearCategories := "DROP table categories"
 	clearGitHubToken := "DROP table githubToken"
+	clearSearchIndex := "DROP table tasks_fts"
+	clearViews := "DROP table views"
 
 	_, _ = testDB.Exec(clearTasks)
 	_, _ = testDB.Exec(clearCategories)
 	_, _ = testDB.Exec(clearGitHubToken)
+	_, _ = testDB.Exec(clearSearchIndex)
+	_, _ = testDB.Exec(clearViews)
 
 	CreateTableTaskCategory()
 	sqlStmt := `CREATE TABLE IF NOT EXISTS githubToken (
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter represents a parsed filter expression like
+// cat:home due:<3d !done +urgent desc~invoice created:>2026-10-01
+// All terms of a filter have to match (they are combined with AND)
+type Filter struct {
+	terms []filterTerm
+}
+
+// filterTerm is a single part of a filter expression
+// converted into a sql condition with its arguments
+type filterTerm struct {
+	condition string
+	args      []interface{}
+}
+
+// FilterError gets returned if a filter expression could not be parsed
+type FilterError struct {
+	Term   string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("invalid filter term %q: %s", e.Term, e.Reason)
+}
+
+// filterDateLayout is the layout of absolute dates in a filter expression
+const filterDateLayout = "2006-01-02"
+
+// ParseFilter parses the given expression into a Filter.
+// Supported terms are:
+//
+//	cat:NAME              tasks of the category NAME
+//	due:OP VALUE          tasks due before/after VALUE, OP is one of < <= > >= =
+//	due:none              tasks without a due date
+//	created:OP VALUE      tasks created before/after VALUE
+//	done / open           finished or unfinished tasks
+//	pri:H                 tasks with the priority H, M, L or none
+//	+TAG                  tasks tagged with TAG
+//	desc~TEXT             tasks whose description contains TEXT
+//	id:1,2,3              tasks with the given ids
+//	TEXT                  same as desc~TEXT
+//
+// VALUE is either a date (2006-01-02), today, tomorrow, yesterday, now or a
+// time relative to now like 3d, 12h, 2w or -1d.
+// Every term can be negated by prefixing it with "!"
+func ParseFilter(expr string) (*Filter, error) {
+	f := &Filter{}
+	for _, word := range strings.Fields(expr) {
+		term, err := parseFilterTerm(word, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		f.terms = append(f.terms, term)
+	}
+	return f, nil
+}
+
+// Empty reports whether the filter matches every task
+func (f *Filter) Empty() bool {
+	return f == nil || len(f.terms) == 0
+}
+
+// Where returns the sql condition of the filter and its arguments.
+// The condition refers to the tasks as t and the categories as c
+func (f *Filter) Where() (string, []interface{}) {
+	if f.Empty() {
+		return "1", nil
+	}
+	conditions := make([]string, len(f.terms))
+	var args []interface{}
+	for i, term := range f.terms {
+		conditions[i] = term.condition
+		args = append(args, term.args...)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// parseFilterTerm converts a single word of a filter expression
+// into a filterTerm
+func parseFilterTerm(word string, now time.Time) (filterTerm, error) {
+	negate := false
+	raw := word
+	if strings.HasPrefix(word, "!") {
+		negate = true
+		word = word[1:]
+	}
+	if word == "" {
+		return filterTerm{}, &FilterError{raw, "nothing to negate"}
+	}
+
+	term, err := parseFilterCondition(raw, word, now)
+	if err != nil {
+		return term, err
+	}
+	if negate {
+		term.condition = "NOT " + term.condition
+	}
+	return term, nil
+}
+
+func parseFilterCondition(raw string, word string, now time.Time) (filterTerm, error) {
+	switch {
+	case word == "done":
+		return filterTerm{condition: "(t.done)"}, nil
+	case word == "open":
+		return filterTerm{condition: "(NOT t.done)"}, nil
+	case strings.HasPrefix(word, "+"):
+		tag := normalizeTag(word[1:])
+		if tag == "" {
+			return filterTerm{}, &FilterError{raw, "missing tag name after +"}
+		}
+		return filterTerm{
+			condition: `((' ' || t.tags || ' ') LIKE ? ESCAPE '\')`,
+			args:      []interface{}{"% " + escapeLike(tag) + " %"},
+		}, nil
+	}
+
+	if i := strings.Index(word, "~"); i > 0 && !strings.Contains(word[:i], ":") {
+		key, value := word[:i], word[i+1:]
+		if key != "desc" && key != "description" {
+			return filterTerm{}, &FilterError{raw, fmt.Sprintf("unknown key %q, only desc supports ~", key)}
+		}
+		return descriptionTerm(raw, value)
+	}
+
+	i := strings.Index(word, ":")
+	if i <= 0 {
+		return descriptionTerm(raw, word)
+	}
+	key, value := word[:i], word[i+1:]
+	if value == "" {
+		return filterTerm{}, &FilterError{raw, fmt.Sprintf("missing value after %s:", key)}
+	}
+
+	switch key {
+	case "cat", "category":
+		return filterTerm{condition: "(c.name = ?)", args: []interface{}{strings.ToLower(value)}}, nil
+	case "due", "until":
+		if value == "none" {
+			return filterTerm{condition: "(t.until = 0)"}, nil
+		}
+		// tasks without a due date never match a comparison
+		return timeTerm(raw, "t.until", value, now, "t.until != 0 AND ")
+	case "created":
+		return timeTerm(raw, "t.created", value, now, "")
+	case "pri", "priority":
+		priority, err := parsePriority(value)
+		if err != nil {
+			return filterTerm{}, &FilterError{raw, err.Error()}
+		}
+		return filterTerm{condition: "(t.priority = ?)", args: []interface{}{priority}}, nil
+	case "id":
+		ids := strings.Split(value, ",")
+		args := make([]interface{}, len(ids))
+		for i, id := range ids {
+			n, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return filterTerm{}, &FilterError{raw, fmt.Sprintf("%q is not a valid id", id)}
+			}
+			args[i] = n
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+		return filterTerm{condition: fmt.Sprintf("(t.id IN (%s))", placeholders), args: args}, nil
+	default:
+		return filterTerm{}, &FilterError{raw, fmt.Sprintf("unknown key %q, expected one of cat, due, created, pri, id", key)}
+	}
+}
+
+// descriptionTerm matches all tasks whose description contains value
+func descriptionTerm(raw string, value string) (filterTerm, error) {
+	if value == "" {
+		return filterTerm{}, &FilterError{raw, "missing text to search for"}
+	}
+	return filterTerm{
+		condition: `(t.description LIKE ? ESCAPE '\')`,
+		args:      []interface{}{"%" + escapeLike(value) + "%"},
+	}, nil
+}
+
+// timeTerm compares the unix timestamp column with the time given by value,
+// which may start with a comparison operator
+func timeTerm(raw string, column string, value string, now time.Time, prefix string) (filterTerm, error) {
+	op := "="
+	for _, o := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, o) {
+			op = o
+			value = value[len(o):]
+			break
+		}
+	}
+
+	start, end, err := parseFilterTime(value, now)
+	if err != nil {
+		return filterTerm{}, &FilterError{raw, err.Error()}
+	}
+
+	// start and end describe the range [start, end) of the given value,
+	// for a date this is the whole day, for a relative time a single second
+	var condition string
+	var args []interface{}
+	switch op {
+	case "<":
+		condition, args = column+" < ?", []interface{}{start}
+	case "<=":
+		condition, args = column+" < ?", []interface{}{end}
+	case ">":
+		condition, args = column+" >= ?", []interface{}{end}
+	case ">=":
+		condition, args = column+" >= ?", []interface{}{start}
+	default:
+		condition, args = column+" >= ? AND "+column+" < ?", []interface{}{start, end}
+	}
+
+	return filterTerm{condition: "(" + prefix + condition + ")", args: args}, nil
+}
+
+// parseFilterTime converts the value of a due or created term into
+// the unix timestamps of the start and the end of the described time
+func parseFilterTime(value string, now time.Time) (int64, int64, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "now":
+		return now.Unix(), now.Unix() + 1, nil
+	case "today":
+		return today.Unix(), today.AddDate(0, 0, 1).Unix(), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1).Unix(), today.AddDate(0, 0, 2).Unix(), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1).Unix(), today.Unix(), nil
+	}
+
+	if day, err := time.ParseInLocation(filterDateLayout, value, now.Location()); err == nil {
+		return day.Unix(), day.AddDate(0, 0, 1).Unix(), nil
+	}
+
+	if d, ok := parseRelativeDuration(value); ok {
+		t := now.Add(d).Unix()
+		return t, t + 1, nil
+	}
+
+	return 0, 0, fmt.Errorf("%q is neither a date (%s), today, tomorrow, yesterday, now nor a relative time like 3d, 12h or 2w",
+		value, filterDateLayout)
+}
+
+// parseRelativeDuration parses durations like 3d, 12h, 2w or -1d
+func parseRelativeDuration(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return 0, false
+	}
+	switch value[len(value)-1] {
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// normalizeTag converts a tag into the form it is stored in the database
+func normalizeTag(tag string) string {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "+")
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"all terms", "cat:home due:<3d !done +urgent desc~invoice created:>2026-10-01 id:1,2", false},
+		{"bare word", "invoice", false},
+		{"unknown key", "foo:bar", true},
+		{"missing value", "cat:", true},
+		{"invalid date", "due:<31.12.2019", true},
+		{"invalid id", "id:1,a", true},
+		{"missing tag", "+", true},
+		{"only negation", "!", true},
+		{"unknown key with ~", "cat~home", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseFilterTime(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.Local)
+	today := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		value     string
+		wantStart int64
+		wantEnd   int64
+		wantErr   bool
+	}{
+		{"today", today.Unix(), today.AddDate(0, 0, 1).Unix(), false},
+		{"tomorrow", today.AddDate(0, 0, 1).Unix(), today.AddDate(0, 0, 2).Unix(), false},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local).Unix(), time.Date(2026, 10, 2, 0, 0, 0, 0, time.Local).Unix(), false},
+		{"3d", now.Unix() + 3*86400, now.Unix() + 3*86400 + 1, false},
+		{"-12h", now.Unix() - 12*3600, now.Unix() - 12*3600 + 1, false},
+		{"1w", now.Unix() + 7*86400, now.Unix() + 7*86400 + 1, false},
+		{"3y", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := parseFilterTime(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseFilterTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("parseFilterTime() = %d, %d, want %d, %d", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	SaveTask("Coding", "Fix 100% of the bugs", 2, 0, "urgent")
+	TaskDone([]string{"2"})
+
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{"", 4},
+		{"cat:home", 2},
+		{"!cat:home", 2},
+		{"done", 1},
+		{"open cat:coding", 1},
+		{"+urgent", 1},
+		{"!+urgent", 3},
+		{"desc~clean", 1},
+		{"100%", 1},
+		{"due:<3d", 1},
+		{"due:>3d", 0},
+		{"due:none", 3},
+		{"created:today", 4},
+		{"created:<yesterday", 0},
+		{"id:1,3", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FilterTasks(filter, "id", "ASC"); len(got) != tt.want {
+				t.Errorf("FilterTasks(%q) returned %d tasks, want %d", tt.expr, len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestAddTags(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	AddTags([]string{"1", "2"}, []string{"Urgent", "work"})
+	AddTags([]string{"1"}, []string{"urgent"})
+
+	tasks := AllTasks("id", "ASC")
+	if got := len(tasks[0].Tags); got != 2 {
+		t.Errorf("Got %d tags %v, expected 2", got, tasks[0].Tags)
+	}
+	if got := len(tasks[2].Tags); got != 0 {
+		t.Errorf("Got %d tags %v, expected 0", got, tasks[2].Tags)
+	}
+}
@@ -77,7 +77,9 @@ func saveIssuesToDatabaseImplementation(url string, token string) {
 	categoryName := "Github"
 	for _, i := range issues {
 		description := fmt.Sprintf("%s: %s", i.Repo.Name, i.Title)
-		SaveTask(categoryName, description, -1, -1)
+		if _, err := SaveTask(categoryName, description, -1, -1); err != nil {
+			panic(err)
+		}
 	}
 
 }
@@ -115,8 +117,8 @@ func saveGitToken(token string) error {
 	}
 
 	createTokenTable()
-	sqlStmt := fmt.Sprintf(`INSERT OR REPLACE INTO githubToken(id, token) VALUES ('%d', '%s');`, 0, token)
-	_, err := db.Exec(sqlStmt)
+	sqlStmt := `INSERT OR REPLACE INTO githubToken(id, token) VALUES (0, $1);`
+	_, err := db.Exec(sqlStmt, token)
 	checkErrorQueries(err, sqlStmt)
-	return nil
+	return err
 }
@@ -1,11 +1,10 @@
 module github.com/Zarathustra2/gtask
 
-go 1.12
+go 1.13
 
 require (
-	github.com/gookit/color v1.1.7
 	github.com/logrusorgru/aurora v0.0.0-20190428105938-cea283e61946
-	github.com/mattn/go-runewidth v0.0.4 // indirect
+	github.com/mattn/go-runewidth v0.0.4
 	github.com/mattn/go-sqlite3 v1.11.0
 	github.com/olekukonko/tablewriter v0.0.1
 )
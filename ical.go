@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layouts of the dates of iCalendar, dates with time are written in UTC
+const (
+	icalDateTime = "20060102T150405Z"
+	icalLocal    = "20060102T150405"
+	icalDate     = "20060102"
+)
+
+// icalLineLength is the amount of bytes after which lines get folded
+const icalLineLength = 75
+
+// statuses of a VTODO
+const (
+	icalNeedsAction = "NEEDS-ACTION"
+	icalCompleted   = "COMPLETED"
+	icalCancelled   = "CANCELLED"
+)
+
+// icalDependsOn is the relation type of a task the VTODO depends on
+const icalDependsOn = "DEPENDS-ON"
+
+// icalEscape escapes a text value of iCalendar
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icalUnescape reverses icalEscape
+func icalUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// icalSplit splits a list of text values at the commas which are not escaped
+func icalSplit(s string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, icalUnescape(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, icalUnescape(s[start:]))
+}
+
+// icalFold folds the line after every icalLineLength bytes without splitting characters
+func icalFold(line string) string {
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		n := len(string(r))
+		if length+n > icalLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += n
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// icalPriority converts a priority into the one of iCalendar, 1 is the highest and 0 none
+func icalPriority(priority int) int {
+	switch priority {
+	case PriorityHigh:
+		return 1
+	case PriorityMedium:
+		return 5
+	case PriorityLow:
+		return 9
+	}
+	return 0
+}
+
+// icalPriorityOf converts a priority of iCalendar, 1 to 4 are high and 6 to 9 low
+func icalPriorityOf(priority int) int {
+	switch {
+	case priority >= 1 && priority <= 4:
+		return PriorityHigh
+	case priority == 5:
+		return PriorityMedium
+	case priority >= 6 && priority <= 9:
+		return PriorityLow
+	}
+	return PriorityNone
+}
+
+// WriteICal writes the tasks as VTODO components of a calendar.
+// The categories are the category followed by the tags, the default category is left out without tags
+func WriteICal(w io.Writer, tasks []Task) error {
+	uuids := uuidsById(tasks)
+	stamp := time.Now().UTC().Format(icalDateTime)
+	date := func(timestamp int64) string {
+		return convertDate(timestamp).UTC().Format(icalDateTime)
+	}
+
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//gtask//gtask//EN"}
+	for _, t := range tasks {
+		lines = append(lines, "BEGIN:VTODO", "UID:"+t.Uuid, "DTSTAMP:"+stamp, "SUMMARY:"+icalEscape(t.Description))
+		if t.Created != 0 {
+			lines = append(lines, "CREATED:"+date(t.Created))
+		}
+		if t.Notes != "" {
+			lines = append(lines, "DESCRIPTION:"+icalEscape(t.Notes))
+		}
+		if t.Until != 0 {
+			lines = append(lines, "DUE:"+date(t.Until))
+		}
+		if t.Done {
+			lines = append(lines, "STATUS:"+icalCompleted)
+			if t.Completed != 0 {
+				lines = append(lines, "COMPLETED:"+date(t.Completed))
+			}
+		} else {
+			lines = append(lines, "STATUS:"+icalNeedsAction)
+		}
+		if t.CategoryName != "" && (t.CategoryName != "default" || len(t.Tags) > 0) {
+			categories := []string{icalEscape(t.CategoryName)}
+			for _, tag := range t.Tags {
+				categories = append(categories, icalEscape(tag))
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		if p := icalPriority(t.Priority); p != 0 {
+			lines = append(lines, "PRIORITY:"+strconv.Itoa(p))
+		}
+		for _, id := range t.Depends {
+			if uuid, ok := uuids[id]; ok {
+				lines = append(lines, "RELATED-TO;RELTYPE="+icalDependsOn+":"+uuid)
+			}
+		}
+		lines = append(lines, "END:VTODO")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icalFold(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icalProperty is a content line of iCalendar like DUE;VALUE=DATE:20261021
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICalProperty parses an unfolded content line
+func parseICalProperty(line string) icalProperty {
+	p := icalProperty{params: make(map[string]string)}
+	// the value starts at the first colon which is not quoted in a parameter
+	quoted, colon := false, len(line)
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	p.value = strings.TrimPrefix(line[colon:], ":")
+
+	parts := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		if i := strings.Index(param, "="); i > 0 {
+			p.params[strings.ToUpper(param[:i])] = strings.Trim(param[i+1:], `"`)
+		}
+	}
+	return p
+}
+
+// time parses the value of the property as date, a date with time in UTC,
+// in the time zone given by TZID or in the local time zone
+func (p *icalProperty) time() (int64, error) {
+	// the Z of icalDateTime is a literal for the layout, so the time has to be parsed in UTC
+	if t, err := time.ParseInLocation(icalDateTime, p.value, time.UTC); err == nil {
+		return t.Unix(), nil
+	}
+	location := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			location = l
+		}
+	}
+	for _, layout := range []string{icalLocal, icalDate} {
+		if t, err := time.ParseInLocation(layout, p.value, location); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid date %s of %s", p.value, p.name)
+}
+
+// icalLines reads the content lines of iCalendar and unfolds them
+func icalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// ReadICal reads the VTODO components of the calendar at path. The first category is the category,
+// the others are tags. Cancelled tasks and tasks without summary are skipped
+func ReadICal(path string) ([]Task, []string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	lines, err := icalLines(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var todos [][]icalProperty
+	inTodo := false
+	for _, line := range lines {
+		p := parseICalProperty(line)
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO"):
+			inTodo = true
+			todos = append(todos, nil)
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO"):
+			inTodo = false
+		case inTodo:
+			todos[len(todos)-1] = append(todos[len(todos)-1], p)
+		}
+	}
+
+	// the tasks get numbered to reference each other by id
+	ids := make(map[string]int64)
+	for i, todo := range todos {
+		for _, p := range todo {
+			if p.name == "UID" {
+				ids[p.value] = int64(i + 1)
+			}
+		}
+	}
+
+	var tasks []Task
+	var skipped []string
+	for i, todo := range todos {
+		t := Task{Id: int64(i + 1)}
+		var categories []string
+		status := ""
+		for _, p := range todo {
+			switch p.name {
+			case "UID":
+				t.Uuid = p.value
+			case "SUMMARY":
+				t.Description = strings.TrimSpace(icalUnescape(p.value))
+			case "DESCRIPTION":
+				t.Notes = icalUnescape(p.value)
+			case "CREATED":
+				t.Created, err = p.time()
+			case "DUE":
+				t.Until, err = p.time()
+			case "COMPLETED":
+				t.Completed, err = p.time()
+			case "STATUS":
+				status = strings.ToUpper(p.value)
+			case "CATEGORIES":
+				categories = append(categories, icalSplit(p.value)...)
+			case "PRIORITY":
+				var priority int
+				priority, err = strconv.Atoi(p.value)
+				t.Priority = icalPriorityOf(priority)
+			case "RELATED-TO":
+				if id, ok := ids[p.value]; ok && strings.EqualFold(p.params["RELTYPE"], icalDependsOn) {
+					t.Depends = append(t.Depends, id)
+				}
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid task %q: %s", t.Description, err)
+			}
+		}
+
+		switch {
+		case status == icalCancelled:
+			skipped = append(skipped, fmt.Sprintf("cancelled task %q", t.Description))
+			continue
+		case t.Description == "":
+			skipped = append(skipped, fmt.Sprintf("task %d without summary", i+1))
+			continue
+		}
+		t.Done = status == icalCompleted || status == "" && t.Completed != 0
+		if !t.Done {
+			t.Completed = 0
+		}
+		for j, c := range categories {
+			if j == 0 {
+				t.CategoryName = c
+			} else if tag := normalizeTag(c); tag != "" {
+				t.Tags = append(t.Tags, tag)
+			}
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, skipped, nil
+}
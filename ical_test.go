@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_icalFold(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "SUMMARY:Clean Room", "SUMMARY:Clean Room\r\n"},
+		{"long", "SUMMARY:" + strings.Repeat("a", 70), "SUMMARY:" + strings.Repeat("a", 67) + "\r\n " + strings.Repeat("a", 3) + "\r\n"},
+		{"multi-byte", "SUMMARY:" + strings.Repeat("a", 66) + "äö", "SUMMARY:" + strings.Repeat("a", 66) + "\r\n äö\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := icalFold(tt.line); got != tt.want {
+				t.Errorf("icalFold() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_icalSplit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"home", []string{"home"}},
+		{"home,weekend", []string{"home", "weekend"}},
+		{`bread\, milk,a\;b\\`, []string{"bread, milk", `a;b\`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := icalSplit(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("icalSplit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadICal(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	// times in UTC must not depend on the local time zone
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local, err = time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Example//Tasks//EN
+BEGIN:VEVENT
+UID:e1
+SUMMARY:Meeting
+END:VEVENT
+BEGIN:VTODO
+UID:a1
+SUMMARY:Paint the fence\, white
+DESCRIPTION:buy paint\nand brus
+ hes
+CREATED:20261019T100000Z
+DUE;TZID=Europe/Berlin:20261020T140000
+PRIORITY:2
+CATEGORIES:Home,Weekend
+RELATED-TO;RELTYPE=DEPENDS-ON:b2
+END:VTODO
+BEGIN:VTODO
+UID:b2
+SUMMARY:Buy brushes
+DUE;VALUE=DATE:20261021
+STATUS:COMPLETED
+COMPLETED:20261019T090000Z
+PRIORITY:7
+END:VTODO
+BEGIN:VTODO
+UID:c3
+SUMMARY:Old idea
+STATUS:CANCELLED
+END:VTODO
+BEGIN:VTODO
+UID:d4
+STATUS:NEEDS-ACTION
+RELATED-TO:a1
+END:VTODO
+END:VCALENDAR
+`, "\n", "\r\n"))
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, skipped, err := ReadICal(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Id: 1, Uuid: "a1", Description: "Paint the fence, white", Notes: "buy paint\nand brushes", CategoryName: "Home", Tags: []string{"weekend"},
+			Priority: PriorityHigh, Created: time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC).Unix(), Until: time.Date(2026, 10, 20, 14, 0, 0, 0, berlin).Unix(), Depends: []int64{2}},
+		{Id: 2, Uuid: "b2", Description: "Buy brushes", Done: true, Priority: PriorityLow,
+			Until: time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local).Unix(), Completed: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC).Unix()},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("Got %+v, want %+v", tasks, want)
+	}
+	if want := []string{`cancelled task "Old idea"`, "task 4 without summary"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Got the skipped tasks %v, want %v", skipped, want)
+	}
+}
+
+func TestWriteICal(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	SetDepends(idFlags{"3"}, []int64{1})
+	SetNotes(idFlags{"1"}, "first, second\nthird")
+	AddTags(idFlags{"1"}, []string{"weekend"})
+	SetPriority(idFlags{"1"}, PriorityMedium)
+	TaskDone(idFlags{"2"})
+
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, AllTasks("id", "ASC")); err != nil {
+		t.Fatal(err)
+	}
+	ics := buf.String()
+	for _, line := range []string{"BEGIN:VCALENDAR\r\n", "DESCRIPTION:first\\, second\\nthird\r\n", "CATEGORIES:home,weekend\r\n",
+		"PRIORITY:5\r\n", "STATUS:COMPLETED\r\n", "STATUS:NEEDS-ACTION\r\n", "END:VCALENDAR\r\n"} {
+		if !strings.Contains(ics, line) {
+			t.Errorf("Expected %q in %q", line, ics)
+		}
+	}
+	if strings.Count(ics, "BEGIN:VTODO") != 3 {
+		t.Errorf("Expected 3 VTODO components in %q", ics)
+	}
+
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(buf.Bytes())
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, skipped, err := ReadICal(file.Name())
+	if err != nil || len(skipped) > 0 {
+		t.Fatal(err, skipped)
+	}
+	saved := AllTasks("id", "ASC")
+	for i := range saved {
+		if saved[i].Uuid == "" || tasks[i].Uuid != saved[i].Uuid {
+			t.Errorf("Got the uid %q, expected the uuid %q of the task", tasks[i].Uuid, saved[i].Uuid)
+		}
+		if tasks[i].Description != saved[i].Description || tasks[i].Notes != saved[i].Notes || tasks[i].Done != saved[i].Done ||
+			tasks[i].CategoryName != saved[i].CategoryName || tasks[i].Priority != saved[i].Priority || tasks[i].Created != saved[i].Created {
+			t.Errorf("Got %+v, want %+v", tasks[i], saved[i])
+		}
+	}
+	if !reflect.DeepEqual(tasks[0].Tags, []string{"weekend"}) || !reflect.DeepEqual(tasks[2].Depends, []int64{1}) {
+		t.Errorf("Got %+v and %+v, expected the tags and depends to be kept", tasks[0], tasks[2])
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// taskJSON is the JSON representation of a Task.
+// The field names are part of the interface for scripts and must not change
+type taskJSON struct {
+	Id          int64    `json:"id"`
+	Description string   `json:"description"`
+	Done        bool     `json:"done"`
+	Created     string   `json:"created"`
+	Until       *string  `json:"until"`
+	Completed   *string  `json:"completed"`
+	CategoryId  int64    `json:"category_id"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Notes       string   `json:"notes"`
+	Priority    string   `json:"priority"`
+	Depends     []int64  `json:"depends"`
+	Blocked     bool     `json:"blocked"`
+	Uuid        string   `json:"uuid,omitempty"`
+}
+
+// newTaskJSON converts the task into its JSON representation
+func newTaskJSON(task *Task) *taskJSON {
+	t := &taskJSON{
+		Id:          task.Id,
+		Description: task.Description,
+		Done:        task.Done,
+		Created:     formatTimestamp(task.Created),
+		CategoryId:  task.CategoryId,
+		Category:    task.CategoryName,
+		Tags:        task.Tags,
+		Notes:       task.Notes,
+		Priority:    priorityLetter(task.Priority),
+		Depends:     task.Depends,
+		Blocked:     task.Blocked,
+		Uuid:        task.Uuid,
+	}
+	if task.Until != 0 {
+		until := formatTimestamp(task.Until)
+		t.Until = &until
+	}
+	if task.Completed != 0 {
+		completed := formatTimestamp(task.Completed)
+		t.Completed = &completed
+	}
+	// scripts should not have to handle null lists
+	if t.Tags == nil {
+		t.Tags = []string{}
+	}
+	if t.Depends == nil {
+		t.Depends = []int64{}
+	}
+	return t
+}
+
+// MarshalJSON encodes the task with timestamps in RFC 3339
+func (task Task) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newTaskJSON(&task))
+}
+
+// UnmarshalJSON decodes a task encoded by MarshalJSON
+func (task *Task) UnmarshalJSON(data []byte) error {
+	var t taskJSON
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+
+	priority, err := parsePriority(t.Priority)
+	if err != nil {
+		return err
+	}
+	created, err := parseTimestamp(t.Created)
+	if err != nil {
+		return err
+	}
+	var until, completed int64
+	if t.Until != nil {
+		if until, err = parseTimestamp(*t.Until); err != nil {
+			return err
+		}
+	}
+	if t.Completed != nil {
+		if completed, err = parseTimestamp(*t.Completed); err != nil {
+			return err
+		}
+	}
+
+	*task = Task{
+		Id:           t.Id,
+		Description:  t.Description,
+		Created:      created,
+		Until:        until,
+		Done:         t.Done,
+		Completed:    completed,
+		CategoryId:   t.CategoryId,
+		CategoryName: t.Category,
+		Tags:         t.Tags,
+		Notes:        t.Notes,
+		Priority:     priority,
+		Depends:      t.Depends,
+		Blocked:      t.Blocked,
+		Uuid:         t.Uuid,
+	}
+	return nil
+}
+
+// MarshalJSON encodes the search result as its task with the rank
+// and the snippet of the notes, the match markers get removed
+func (r SearchResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*taskJSON
+		Rank    float64 `json:"rank"`
+		Snippet string  `json:"snippet"`
+	}{newTaskJSON(&r.Task), r.Rank, stripMatches(r.Snippet)})
+}
+
+// nextJSON is the JSON representation of a task shown by the next command
+type nextJSON struct {
+	*taskJSON
+	Urgency float64         `json:"urgency"`
+	Reasons []UrgencyReason `json:"reasons"`
+}
+
+// newNextJSON converts the tasks into their JSON representation with their urgency
+func newNextJSON(tasks []Task, c UrgencyCoefficients, now time.Time) []nextJSON {
+	next := make([]nextJSON, len(tasks))
+	for i := range tasks {
+		score, reasons := c.Urgency(&tasks[i], now)
+		if reasons == nil {
+			reasons = []UrgencyReason{}
+		}
+		next[i] = nextJSON{newTaskJSON(&tasks[i]), score, reasons}
+	}
+	return next
+}
+
+// formatTimestamp formats a unix timestamp in RFC 3339
+func formatTimestamp(unixTimestamp int64) string {
+	return convertDate(unixTimestamp).Format(time.RFC3339)
+}
+
+// parseTimestamp parses a time in RFC 3339 into a unix timestamp,
+// an empty string is 0
+func parseTimestamp(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected RFC 3339 like 2026-10-21T15:04:05+02:00", s)
+	}
+	return t.Unix(), nil
+}
+
+// stripMatches removes the match markers of the search index
+func stripMatches(s string) string {
+	return strings.NewReplacer(matchStart, "", matchEnd, "").Replace(s)
+}
+
+// RenderJSON writes the slice values as an indented JSON array or,
+// if ndjson is set, as one JSON object per line. Values which are no slice are a single object
+func RenderJSON(values interface{}, ndjson bool) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	if !ndjson {
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	}
+
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		return encoder.Encode(values)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderJSON renders the slice values as JSON or NDJSON depending on the flags
+// and exits if they can not be encoded
+func renderJSON(values interface{}) {
+	if err := RenderJSON(values, outputFormat() == formatNDJSON); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTask_MarshalJSON(t *testing.T) {
+	created := time.Date(2026, 10, 19, 15, 4, 5, 0, time.Local)
+	task := Task{
+		Id:           3,
+		Description:  "Clean dishes",
+		Created:      created.Unix(),
+		Done:         true,
+		Completed:    created.Add(time.Hour).Unix(),
+		CategoryId:   2,
+		CategoryName: "home",
+		Priority:     PriorityMedium,
+	}
+
+	got, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(got, &fields); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"id":          3.0,
+		"description": "Clean dishes",
+		"done":        true,
+		"created":     created.Format(time.RFC3339),
+		"until":       nil,
+		"completed":   created.Add(time.Hour).Format(time.RFC3339),
+		"category_id": 2.0,
+		"category":    "home",
+		"tags":        []interface{}{},
+		"notes":       "",
+		"priority":    "M",
+		"depends":     []interface{}{},
+		"blocked":     false,
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Got %v, want %v", fields, want)
+	}
+
+	var decoded Task
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	task.Tags, task.Depends = []string{}, []int64{}
+	if !reflect.DeepEqual(decoded, task) {
+		t.Errorf("Decoded %v, want %v", decoded, task)
+	}
+}
+
+func TestTask_UnmarshalJSONInvalid(t *testing.T) {
+	tests := []string{
+		`{"created": "yesterday"}`,
+		`{"until": "21.10.2026"}`,
+		`{"priority": "urgent"}`,
+	}
+	for _, data := range tests {
+		var task Task
+		if err := json.Unmarshal([]byte(data), &task); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	tasks := AllTasks("id", "ASC")
+	if err := RenderJSON(tasks, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Errorf("Got %d lines of NDJSON, expected 3", len(lines))
+	}
+
+	buf.Reset()
+	if err := RenderJSON(Stats{Period: "week"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("Got %d lines of NDJSON for a single object, expected 1", got)
+	}
+
+	buf.Reset()
+	if err := RenderJSON(AllCategories(), false); err != nil {
+		t.Fatal(err)
+	}
+	var categories []Category
+	if err := json.Unmarshal(buf.Bytes(), &categories); err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 3 || categories[1] != (Category{2, "home"}) {
+		t.Errorf("Got %v, expected the three categories", categories)
+	}
+	if strings.Contains(buf.String(), "\x1b") {
+		t.Errorf("Expected no colour codes in %q", buf.String())
+	}
+}
+
+func TestSearchResult_MarshalJSON(t *testing.T) {
+	r := SearchResult{Task: Task{Id: 1}, Rank: 2.5, Snippet: "the " + matchStart + "invoice" + matchEnd}
+	got, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `"snippet":"the invoice"`) || !strings.Contains(string(got), `"id":1`) {
+		t.Errorf("Got %s, expected the task with the snippet without markers", got)
+	}
+}
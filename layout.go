@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// termWidth is the amount of cells the output has to fit in, 0 if it is not limited
+var termWidth int
+
+// minDescriptionWidth is the width descriptions get at least, even if the terminal is narrower
+const minDescriptionWidth = 10
+
+// outputWidth returns the width the output has to fit in. A width given by flag is used as is,
+// otherwise the width of the terminal is detected. Output which does not go to a terminal
+// is not limited unless COLUMNS is set
+func outputWidth(flagWidth int) int {
+	if flagWidth > 0 {
+		return flagWidth
+	}
+	if os.Getenv("COLUMNS") == "" && !isTerminal(os.Stdout) {
+		return 0
+	}
+	width, _ := terminalSize()
+	return width
+}
+
+// wrapText breaks s into lines of at most width cells at spaces,
+// words which are longer than width get split
+func wrapText(s string, width int) []string {
+	words := strings.Fields(s)
+	if width <= 0 || len(words) == 0 {
+		return []string{strings.Join(words, " ")}
+	}
+
+	var lines []string
+	line, lineWidth := "", 0
+	for _, word := range words {
+		w := runewidth.StringWidth(word)
+		if lineWidth > 0 && lineWidth+1+w <= width {
+			line += " " + word
+			lineWidth += 1 + w
+			continue
+		}
+		if lineWidth > 0 {
+			lines = append(lines, line)
+		}
+		for w > width {
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				// the first character alone is wider than width
+				_, size := utf8.DecodeRuneInString(word)
+				head = word[:size]
+			}
+			lines = append(lines, head)
+			word = word[len(head):]
+			w = runewidth.StringWidth(word)
+		}
+		line, lineWidth = word, w
+	}
+	return append(lines, line)
+}
+
+// fitText wraps s into lines of at most width cells or,
+// if truncate is set, cuts it off after width cells
+func fitText(s string, width int, truncate bool) []string {
+	if !truncate {
+		return wrapText(s, width)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if width <= 0 {
+		return []string{s}
+	}
+	return []string{runewidth.Truncate(s, width, theme.Ellipsis)}
+}
+
+// taskPrefix returns the check box and the id of the task
+// which precede its description in the aligned views
+func taskPrefix(t *Task) string {
+	if config.Compact {
+		return fmt.Sprintf("%s %d ", t.getCheckBox(), t.Id)
+	}
+	return fmt.Sprintf("%s  %d ", padLeft(t.getCheckBox(), 6), t.Id)
+}
+
+// layoutLines lays out a task of the aligned view: the prefix followed by the text,
+// which gets fitted into the width, and the due date aligned to the right edge.
+// The wrapped lines are indented by the width of the prefix and painted with the style
+func layoutLines(prefix string, text string, style string, due string, width int, truncate bool) []string {
+	prefixWidth := visibleWidth(prefix)
+	dueWidth := visibleWidth(due)
+	if due != "" {
+		dueWidth += 2
+	}
+
+	available := 0
+	if width > 0 {
+		available = width - prefixWidth - dueWidth
+		if available < minDescriptionWidth {
+			available = minDescriptionWidth
+		}
+	}
+
+	lines := fitText(text, available, truncate)
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = prefix + paint(style, line)
+		} else {
+			lines[i] = strings.Repeat(" ", prefixWidth) + paint(style, line)
+		}
+	}
+	if due == "" {
+		return lines
+	}
+	gap := width - visibleWidth(lines[0]) - visibleWidth(due)
+	if gap < 2 {
+		gap = 2
+	}
+	lines[0] += strings.Repeat(" ", gap) + due
+	return lines
+}
+
+// fitTableColumn fits the values of the column into the space the other columns
+// of the table leave in the width, each column takes up its widest value and 3 cells
+// for the padding and the separator
+func fitTableColumn(header []string, data [][]string, column int, width int, truncate bool) {
+	used := 0
+	for j := range header {
+		if j == column {
+			continue
+		}
+		columnWidth := visibleWidth(header[j])
+		for _, row := range data {
+			if w := visibleWidth(row[j]); w > columnWidth {
+				columnWidth = w
+			}
+		}
+		used += columnWidth + 3
+	}
+
+	available := width - used - 3
+	if available < minDescriptionWidth {
+		available = minDescriptionWidth
+	}
+	for _, row := range data {
+		row[column] = strings.Join(fitText(row[column], available, truncate), "\n")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_wrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"fits", "Clean Room", 20, []string{"Clean Room"}},
+		{"unlimited", "Clean  my\nRoom", 0, []string{"Clean my Room"}},
+		{"wrap", "Clean my room today", 10, []string{"Clean my", "room today"}},
+		{"long word", "abcdefghij klm", 4, []string{"abcd", "efgh", "ij", "klm"}},
+		{"wide characters", "日本語の説明", 5, []string{"日本", "語の", "説明"}},
+		{"empty", "", 10, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fitText(t *testing.T) {
+	if got := fitText("Clean my room today", 10, true); !reflect.DeepEqual(got, []string{"Clean my …"}) {
+		t.Errorf("Got %q, expected the truncated description", got)
+	}
+	if got := fitText("Clean my room today", 0, true); !reflect.DeepEqual(got, []string{"Clean my room today"}) {
+		t.Errorf("Got %q, expected the full description", got)
+	}
+}
+
+func Test_layoutLines(t *testing.T) {
+	setColors(false)
+	defer setColors(true)
+
+	tests := []struct {
+		name     string
+		due      string
+		width    int
+		truncate bool
+		want     []string
+	}{
+		{"unlimited", "2d", 0, false, []string{"  1 Clean my room today  2d"}},
+		{"right aligned", "2d", 30, false, []string{"  1 Clean my room today     2d"}},
+		{"wrapped", "2d", 20, false, []string{"  1 Clean my      2d", "    room today"}},
+		{"truncated", "", 14, true, []string{"  1 Clean my …"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := layoutLines("  1 ", "Clean my room today", "", tt.due, tt.width, tt.truncate)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layoutLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fitTableColumn(t *testing.T) {
+	header := []string{"ID", "Description"}
+	data := [][]string{{"1", "Clean my room today"}, {"12", "Pay"}}
+
+	fitTableColumn(header, data, 1, 18, false)
+
+	if got := data[0][1]; got != "Clean my\nroom today" {
+		t.Errorf("Got %q, expected the wrapped description", got)
+	}
+	if got := data[1][1]; got != "Pay" {
+		t.Errorf("Got %q, expected the short description unchanged", got)
+	}
+	for _, line := range strings.Split(data[0][1], "\n") {
+		if visibleWidth(line) > minDescriptionWidth {
+			t.Errorf("Line %q is wider than the available space", line)
+		}
+	}
+}
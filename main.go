@@ -3,7 +3,10 @@ package main
 import (
 	"database/sql"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -28,8 +31,57 @@ var (
 	gitIssuesDownload bool
 	table             bool
 	addGitToken       string
+	tagList           string
+	note              string
+	page              int
+	offset            int
+	noPager           bool
+	categoryOrder     string
+	pinnedCategories  string
+	collapseDone      bool
+	priority          string
+	dependsOn         string
+	columnList        string
+	jsonOutput        bool
+	ndjsonOutput      bool
+	outputFormatName  string
+	templateText      string
+	colorMode         string
+	themeName         string
+	compact           bool
+	truncate          bool
+	width             int
+	undoneIds         bool
+	editDescription   string
+	agendaHorizon     int
+	calendarDay       string
+	statsPeriod       string
+	exportToken       bool
+	conflictStrategy  string
+	icsFormat         bool
+	dryRun            bool
 )
 
+// commands maps the name of a sub command to its implementation,
+// the arguments following the name get passed to the command
+var commands = map[string]func(args []string){
+	"ls":     listCommand,
+	"search": searchCommand,
+	"next":   nextCommand,
+	"view":   viewCommand,
+	"ui":     tuiCommand,
+	"agenda": agendaCommand,
+	"cal":    calendarCommand,
+	"stats":  statsCommand,
+
+	"export":     exportCommand,
+	"import":     importCommand,
+	"heatmap":    heatmapCommand,
+	"burndown":   burndownCommand,
+	"categories": categoriesCommand,
+	"scan":       scanCommand,
+}
+
 // idFlags represents a list of ids
 type idFlags []string
 
@@ -37,41 +89,82 @@ func (i *idFlags) String() string {
 	return strings.Join(*i, ", ")
 }
 
+// Set adds the comma separated ids. They are parsed as numbers as the ids are put into queries
 func (i *idFlags) Set(value string) error {
-	*i = append(*i, value)
+	for _, field := range strings.Split(value, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", field)
+		}
+		*i = append(*i, strconv.FormatInt(id, 10))
+	}
 	return nil
 }
 
-// interpreter chooses based on the given flags the right execution
-func interpreter() {
+// interpreter chooses based on the given flags and arguments the right execution.
+// Arguments which are not a sub command are treated as a filter expression
+func interpreter(args []string) {
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			command(args[1:])
+			return
+		}
+	}
+
 	switch {
 
 	case categoryId > 0:
-		if len(idsLists) == 0 {
-			log.Fatalln("You need to provide IDs to update the category with the ids flag")
-			return
-		}
-		UpdateCategory(categoryId, idsLists)
+		UpdateCategory(categoryId, selectIds(args, "move to the category", "open"))
 
 	case delDoneTasks:
 		DeleteDoneTasks()
 
 	case updateIds:
-		if len(idsLists) == 0 {
-			log.Fatalln("You need to provide IDs to update done tasks with the ids flag")
-			return
-		}
-		TaskDone(idsLists)
+		TaskDone(selectIds(args, "mark as done", "open"))
+
+	case undoneIds:
+		TaskUndone(selectIds(args, "reopen", "done"))
 
 	case description != "":
-		SaveTask(categoryName, description, day, hour)
+		task := newTask(categoryName, description, day, hour)
+		task.Tags = splitTags(tagList)
+		task.Notes = note
+		task.Priority = parsePriorityFlag()
+		task.Depends = splitIds(dependsOn)
+		if err := insertTask(&task); err != nil {
+			log.Fatalln(err)
+		}
+		if task.Id == 0 {
+			log.Fatalf("A task with the description %q already exists\n", description)
+		}
 
 	case deleteIds:
-		if len(idsLists) == 0 {
-			log.Fatalln("You need to provide IDs to delete with the ids flag")
-			return
+		DeleteTasksById(selectIds(args, "delete", "open"))
+
+	case editDescription != "":
+		ids := selectIds(args, "edit", "open")
+		if len(ids) != 1 {
+			log.Fatalf("-edit changes the description of exactly one task, got %d\n", len(ids))
+		}
+		id, err := strconv.ParseInt(ids[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid id %s\n", ids[0])
+		}
+		if err := SetDescription(id, editDescription); err != nil {
+			log.Fatalln(err)
 		}
-		DeleteTasksById(idsLists)
+
+	case tagList != "":
+		AddTags(selectIds(args, "tag", "open"), splitTags(tagList))
+
+	case note != "":
+		SetNotes(selectIds(args, "add a note", "open"), note)
+
+	case priority != "":
+		SetPriority(selectIds(args, "set the priority", "open"), parsePriorityFlag())
+
+	case dependsOn != "":
+		SetDepends(selectIds(args, "set the dependencies", "open"), splitIds(dependsOn))
 
 	case gitIssuesDownload:
 		saveIssuesToDatabase()
@@ -83,13 +176,119 @@ func interpreter() {
 		}
 
 	default:
-		renderTasks(orderBy, table, desc, renderCategories)
+		listCommand(args)
+
+	}
+}
+
+// listCommand renders all tasks matching the filter expression given by args
+func listCommand(args []string) {
+	filter := parseFilterArgs(args)
+	var columns []string
+	if columnList != "" {
+		columns = strings.Split(columnList, ",")
+	}
+	if err := validateColumns(columns); err != nil {
+		log.Fatalln(err)
+	}
+
+	format := outputFormat()
+	if format == formatAligned || format == formatTable {
+		withPager(!noPager, func() {
+			renderTasks(orderBy, format, desc, renderCategories, filter, currentPage(), columns)
+		})
+		return
+	}
+
+	if renderCategories {
+		log.Fatalln("Use gtask categories -json or -ndjson to list the categories")
+	}
+	sorted := "ASC"
+	if desc {
+		sorted = "DESC"
+	}
+	// scripts and documents get all tasks unless they ask for a page
+	p := currentPage()
+	if given := givenFlags(); !given["amount"] && !given["page"] {
+		p.Limit = 0
+	}
+	tasks := FilterTasksPage(filter, orderBy, sorted, p.Limit, p.Offset)
+
+	var err error
+	switch format {
+	case formatTemplate:
+		err = renderTemplate(tasks)
+	case formatCSV:
+		err = RenderCSV(tasks)
+	case formatMarkdown:
+		RenderMarkdown(tasks)
+	default:
+		renderJSON(tasks)
+	}
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// renderTemplate renders the tasks with the template given by the template flag
+func renderTemplate(tasks []Task) error {
+	if templateText == "" {
+		return fmt.Errorf("You need to provide the template with the template flag")
+	}
+	tmpl, err := loadTemplate(templateText, config)
+	if err != nil {
+		return err
+	}
+	return RenderTemplate(tmpl, tasks)
+}
+
+// categoriesCommand renders all categories
+func categoriesCommand(args []string) {
+	switch format := outputFormat(); {
+	case machineOutput():
+		renderJSON(AllCategories())
+	case format == formatAligned || format == formatTable:
+		RenderTableCategories()
+	default:
+		log.Fatalf("The categories can not be listed as %s, use -json or -ndjson\n", format)
+	}
+}
 
+// outputFormat returns the format given by the format flag or one of its shorthands
+func outputFormat() string {
+	switch {
+	case outputFormatName != "":
+		return outputFormatName
+	case templateText != "":
+		return formatTemplate
+	case jsonOutput:
+		return formatJSON
+	case ndjsonOutput:
+		return formatNDJSON
+	case table:
+		return formatTable
+	default:
+		return formatAligned
 	}
 }
 
+// machineOutput reports whether the output should be JSON instead of text for humans
+func machineOutput() bool {
+	format := outputFormat()
+	return format == formatJSON || format == formatNDJSON
+}
+
+// givenFlags returns the names of the flags which were given on the command line
+func givenFlags() map[string]bool {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	return given
+}
+
 // renderTasks renders the tasks. Either as aligned style or as table
-func renderTasks(orderBy string, table bool, desc bool, renderCategories bool) {
+func renderTasks(orderBy string, format string, desc bool, renderCategories bool, filter *Filter, page Page, columns []string) {
 	if renderCategories {
 		RenderTableCategories()
 	}
@@ -98,14 +297,108 @@ func renderTasks(orderBy string, table bool, desc bool, renderCategories bool) {
 		sorted = "DESC"
 	}
 
-	if table {
-		RenderTableTasks(orderBy, sorted)
+	if format == formatTable {
+		RenderTableTasks(orderBy, sorted, filter, page, columns)
 	} else {
-		RenderAligned(orderBy, sorted)
+		RenderAligned(orderBy, sorted, filter, page)
+	}
+}
+
+// currentPage returns the page given by the amount, page and offset flags
+func currentPage() Page {
+	p := Page{Limit: amount, Offset: offset}
+	if p.Limit < 0 {
+		p.Limit = 0
+	}
+	if page > 1 {
+		p.Offset += (page - 1) * p.Limit
+	}
+	return p
+}
+
+// parseFilterArgs parses the arguments as one filter expression
+// and exits with the parse error if it is invalid
+func parseFilterArgs(args []string) *Filter {
+	filter, err := ParseFilter(strings.Join(args, " "))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return filter
+}
+
+// selectIds returns the ids given by the ids flag or, if none were given,
+// the ids of all tasks matching the filter expression in args.
+// Without both the tasks matching pickFrom can be picked on a terminal
+func selectIds(args []string, action string, pickFrom string) idFlags {
+	if len(idsLists) > 0 {
+		return idsLists
+	}
+	if len(args) == 0 {
+		if canPick() {
+			return pickIds(action, pickFrom)
+		}
+		log.Fatalf("You need to provide IDs to %s with the ids flag or a filter\n", action)
+	}
+
+	tasks := FilterTasks(parseFilterArgs(args), "id", "ASC")
+	if len(tasks) == 0 {
+		log.Fatalln("No tasks match the filter")
+	}
+
+	ids := make(idFlags, len(tasks))
+	for i, task := range tasks {
+		ids[i] = strconv.FormatInt(task.Id, 10)
+	}
+	return ids
+}
+
+// parsePriorityFlag parses the priority flag and exits if it is invalid
+func parsePriorityFlag() int {
+	p, err := parsePriority(priority)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return p
+}
+
+// splitTags splits a comma separated list of tags
+func splitTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = normalizeTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseFlags parses the command line flags and returns the remaining arguments.
+// In contrast to flag.Parse flags may also follow the arguments,
+// e.g. gtask ls cat:home -table
+func parseFlags(arguments []string) []string {
+	var args []string
+	for {
+		_ = flag.CommandLine.Parse(arguments)
+		rest := flag.Args()
+		if len(rest) == 0 {
+			return args
+		}
+		// everything after "--" is an argument
+		if len(arguments) > len(rest) && arguments[len(arguments)-len(rest)-1] == "--" {
+			return append(args, rest...)
+		}
+		args = append(args, rest[0])
+		arguments = rest[1:]
 	}
 }
 
 func main() {
+	args := parseFlags(os.Args[1:])
+	enabled, err := useColors(colorMode, noColorSet(), isTerminal(os.Stdout))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	setColors(enabled)
 
 	dir, dbName, fullPath, err := getDbPath("todo")
 	getOrCreateDb(dir, dbName, fullPath)
@@ -116,19 +409,80 @@ func main() {
 
 	defer database.Close()
 
+	configPath = dir + "/config.json"
+	config, err = loadConfig(configPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	applyConfigFlags(&config)
+	if theme, err = resolveTheme(config.Theme, config.Themes); err != nil {
+		log.Fatalln(err)
+	}
+	termWidth = outputWidth(width)
+	if err := validateFormat(outputFormat()); err != nil {
+		log.Fatalln(err)
+	}
+
 	setDB(database)
 	CreateTableTaskCategory()
-	interpreter()
+	interpreter(args)
+
+}
 
+// applyConfigFlags overrides the settings of the config file with the given flags
+func applyConfigFlags(c *Config) {
+	if categoryOrder != "" {
+		c.CategoryOrder = categoryOrder
+	}
+	if pinnedCategories != "" {
+		c.PinnedCategories = strings.Split(pinnedCategories, ",")
+	}
+	if collapseDone {
+		c.CollapseDone = true
+	}
+	if themeName != "" {
+		c.Theme = themeName
+	}
+	if compact {
+		c.Compact = true
+	}
+	if agendaHorizon != 0 {
+		c.AgendaHorizon = agendaHorizon
+	}
+	if truncate {
+		c.Truncate = true
+	}
+	if err := c.validate(); err != nil {
+		log.Fatalln(err)
+	}
 }
 
-// init parses the flags
+// init declares the flags
 func init() {
 
 	flag.BoolVar(&desc, "desc", false, "Sort Asc/Desc, default Asc")
-	flag.IntVar(&amount, "amount", 10, "Amount of Tasks which are shown")
-
-	flag.StringVar(&orderBy, "o", "ID", "Order by, default ID")
+	flag.IntVar(&amount, "amount", 10, "Amount of Tasks which are shown, per category in the aligned view, 0 shows all")
+	flag.IntVar(&page, "page", 1, "Page of tasks which is shown, a page consists of amount tasks")
+	flag.IntVar(&offset, "offset", 0, "Amount of Tasks which are skipped")
+	flag.BoolVar(&noPager, "nopager", false, "Do not show long output in a pager")
+	flag.StringVar(&themeName, "theme", "", "Theme of the output: dark, light, high-contrast, ascii or a theme of the config")
+	flag.StringVar(&colorMode, "color", colorAuto, "Colour the output: auto, always or never, auto respects NO_COLOR and only colours a terminal")
+
+	flag.StringVar(&categoryOrder, "corder", "", "Order of the categories: alpha, urgent, open or pinned")
+	flag.StringVar(&pinnedCategories, "pin", "", "Comma separated categories shown first with -corder pinned")
+	flag.BoolVar(&collapseDone, "collapse", false, "Collapse categories without open tasks")
+	flag.IntVar(&agendaHorizon, "horizon", 0, "Amount of days the agenda shows one by one, default 7")
+	flag.StringVar(&statsPeriod, "period", "week", "Period the stats group the created and completed tasks by: day, week or month")
+	flag.BoolVar(&exportToken, "token", false, "Include the GitHub token in the export")
+	flag.StringVar(&conflictStrategy, "conflict", conflictKeep, "What import does with existing tasks and views: keep, replace or fail")
+	flag.BoolVar(&icsFormat, "ics", false, "Export or import the tasks as iCalendar, same as the format ics")
+	flag.BoolVar(&dryRun, "dryrun", false, "Show what import or scan would write without writing anything")
+	flag.StringVar(&calendarDay, "day", "", "Day of the calendar to list the tasks due on, like 2026-10-21 or today")
+	flag.BoolVar(&compact, "compact", false, "Use less space for narrow terminals")
+	flag.BoolVar(&truncate, "truncate", false, "Cut long descriptions off instead of wrapping them")
+	flag.IntVar(&width, "width", 0, "Width the output has to fit in, default is the width of the terminal")
+
+	flag.StringVar(&orderBy, "o", "ID", "Order by, default ID, urgency lists the most urgent tasks first")
 
 	flag.StringVar(&description, "i", "", "Description of Task")
 	flag.StringVar(&categoryName, "cname", "", "Name of the Category")
@@ -142,13 +496,30 @@ func init() {
 
 	flag.BoolVar(&updateIds, "done", false, "")
 	flag.BoolVar(&deleteIds, "del", false, "")
+	flag.BoolVar(&undoneIds, "undone", false, "Mark the tasks given by ids as open again")
+	flag.StringVar(&editDescription, "edit", "", "New description of the tasks given by ids")
 
-	flag.BoolVar(&table, "table", false, "Show tasks as table")
+	flag.BoolVar(&table, "table", false, "Show tasks as table, same as -format table")
+	flag.StringVar(&outputFormatName, "format", "", "Format of the tasks: aligned, table, json, ndjson, csv, markdown or template")
+	flag.StringVar(&templateText, "template", "", "Go text/template rendering each task, the name of a template in the config or @file")
+	flag.BoolVar(&jsonOutput, "json", false, "Print tasks, categories, search results and views as JSON, same as -format json")
+	flag.BoolVar(&ndjsonOutput, "ndjson", false, "Print tasks, categories, search results and views as JSON, one object per line, same as -format ndjson")
+	flag.StringVar(&columnList, "columns", "", "Comma separated columns of the table: check, id, description, until, category, tags, priority, urgency, created")
 
 	flag.Var(&idsLists, "ids", "IDs of Tasks")
 
 	flag.BoolVar(&gitIssuesDownload, "gitissues", false, "")
 	flag.StringVar(&addGitToken, "gittoken", "", "Your Github Access Token")
 
-	flag.Parse()
+	flag.StringVar(&tagList, "tags", "", "Comma separated tags of a new task or the tasks given by ids")
+	flag.StringVar(&note, "note", "", "Notes of a new task or the tasks given by ids")
+	flag.StringVar(&priority, "p", "", "Priority H, M, L or none of a new task or the tasks given by ids")
+	flag.StringVar(&dependsOn, "dep", "", "Comma separated ids of the tasks a new task or the tasks given by ids depend on")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: gtask [flags] [ls] [filter]\n       gtask search <terms>\n       gtask next [filter]\n       gtask view [save NAME [filter] | rm NAME | NAME [filter]]\n       gtask categories\n       gtask ui [filter]\n       gtask agenda [filter]\n       gtask cal [month] [filter] | -day DATE [filter]\n       gtask stats [filter]\n       gtask heatmap [filter]\n       gtask burndown [filter]\n       gtask export [todotxt | taskwarrior | ics] [file] | import [-dryrun] [todotxt | taskbook | taskwarrior | ics | markdown] [file]\n       gtask scan [-dryrun] [dir]\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "A filter like cat:home due:<3d !done +urgent desc~invoice created:>2026-10-01\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "selects the tasks which are listed, marked as done, deleted or moved.\n\n")
+		flag.PrintDefaults()
+	}
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_idFlags_Set(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    idFlags
+		wantErr bool
+	}{
+		{"3", idFlags{"3"}, false},
+		{"1,2, 3", idFlags{"1", "2", "3"}, false},
+		{"1) OR 1=1 --", nil, true},
+		{"1,", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var ids idFlags
+			err := ids.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Set() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
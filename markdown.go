@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// markdownHeading matches an ATX heading like "## Meeting 2026-10-19 ##"
+	markdownHeading = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	// markdownItem matches an item of a bullet or ordered list which may be a checkbox
+	markdownItem = regexp.MustCompile(`^(\s*)(?:[-*+]|\d{1,9}[.)])\s+(?:\[([ xX])\](?:\s+|$))?(.*)$`)
+	// markdownFence matches the start or end of a fenced code block
+	markdownFence = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// markdownIndent returns the indentation of a line, a tab counts as 4 spaces
+func markdownIndent(s string) int {
+	return len(strings.ReplaceAll(s, "\t", "    "))
+}
+
+// ReadMarkdown reads the checklists of the markdown file at path. Unchecked items are open tasks and
+// checked items done tasks in the category of the nearest heading above them. Checklist items nested
+// in another one are subtasks the task of the outer item depends on, other nested list items are added
+// to its notes. Code blocks are ignored and empty items skipped
+func ReadMarkdown(path string) ([]Task, []string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	// item is a list item which may contain nested items, task is -1 if it is no checklist item
+	type item struct {
+		indent int
+		task   int
+	}
+	var tasks []Task
+	var skipped []string
+	var items []item
+	category, fenced, number := "", false, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		number++
+		if markdownFence.MatchString(line) {
+			fenced = !fenced
+			continue
+		}
+		if fenced || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			category, items = strings.TrimSpace(m[1]), nil
+			continue
+		}
+		m := markdownItem.FindStringSubmatch(line)
+		if m == nil {
+			// an indented line continues the item, anything else ends the list
+			if line[0] != ' ' && line[0] != '\t' {
+				items = nil
+			}
+			continue
+		}
+
+		indent, text := markdownIndent(m[1]), strings.TrimSpace(m[3])
+		for len(items) > 0 && items[len(items)-1].indent >= indent {
+			items = items[:len(items)-1]
+		}
+		parent := -1
+		for i := len(items) - 1; i >= 0 && parent < 0; i-- {
+			parent = items[i].task
+		}
+
+		current := item{indent, -1}
+		switch {
+		case m[2] == "" && parent >= 0 && text != "":
+			p := &tasks[parent]
+			p.Notes = strings.TrimPrefix(p.Notes+"\n"+text, "\n")
+		case m[2] == "":
+		case text == "":
+			skipped = append(skipped, fmt.Sprintf("empty item in line %d", number))
+		default:
+			t := Task{Id: int64(len(tasks) + 1), Description: text, CategoryName: category, Done: m[2] != " "}
+			if parent >= 0 {
+				tasks[parent].Depends = append(tasks[parent].Depends, t.Id)
+			}
+			current.task = len(tasks)
+			tasks = append(tasks, t)
+		}
+		items = append(items, current)
+	}
+	return tasks, skipped, scanner.Err()
+}
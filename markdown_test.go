@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadMarkdown(t *testing.T) {
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("- [ ] Read the minutes\n" +
+		"# Meeting 2026-10-19 #\n" +
+		"\n" +
+		"Attendees: Anna, Ben\n" +
+		"\n" +
+		"- [ ] Send the agenda\n" +
+		"  - [x] Draft it\n" +
+		"  - ask Ben first\n" +
+		"\t- [ ] Book a room\n" +
+		"      - [ ] Check the projector\n" +
+		"- [ ]\n" +
+		"* [X] Order pizza\n" +
+		"\n" +
+		"```\n" +
+		"- [ ] not a task\n" +
+		"```\n" +
+		"## Follow up\n" +
+		"1. [ ] Call the plumber\n" +
+		"- [link](https://example.com)\n" +
+		"  - [ ] Nested in a plain item\n")
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, skipped, err := ReadMarkdown(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Id: 1, Description: "Read the minutes"},
+		{Id: 2, Description: "Send the agenda", CategoryName: "Meeting 2026-10-19", Depends: []int64{3, 4}, Notes: "ask Ben first"},
+		{Id: 3, Description: "Draft it", CategoryName: "Meeting 2026-10-19", Done: true},
+		{Id: 4, Description: "Book a room", CategoryName: "Meeting 2026-10-19", Depends: []int64{5}},
+		{Id: 5, Description: "Check the projector", CategoryName: "Meeting 2026-10-19"},
+		{Id: 6, Description: "Order pizza", CategoryName: "Meeting 2026-10-19", Done: true},
+		{Id: 7, Description: "Call the plumber", CategoryName: "Follow up"},
+		{Id: 8, Description: "Nested in a plain item", CategoryName: "Follow up"},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("Got %+v, want %+v", tasks, want)
+	}
+	if want := []string{"empty item in line 11"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Got the skipped items %v, want %v", skipped, want)
+	}
+}
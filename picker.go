@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// keys of the picker in addition to the keys of the interface
+const (
+	keyTab   = "\t"
+	keyCtrlN = "\x0e"
+	keyCtrlP = "\x10"
+)
+
+// picker is the state of the interactive selection of tasks.
+// Typing narrows the tasks down by fuzzy matching, tab selects several tasks
+type picker struct {
+	title string
+	tasks []Task
+	query string
+	// matches are the indexes of the tasks matching the query, best match first
+	matches []int
+	cursor  int
+	offset  int
+	chosen  map[int64]bool
+
+	done      bool
+	cancelled bool
+}
+
+// newPicker returns a picker over the tasks
+func newPicker(title string, tasks []Task) *picker {
+	p := &picker{title: title, tasks: tasks, chosen: make(map[int64]bool)}
+	p.match()
+	return p
+}
+
+// pickerText is the text of a task the query gets matched against
+func pickerText(t *Task) string {
+	text := fmt.Sprintf("%d %s %s", t.Id, t.Description, t.CategoryName)
+	for _, tag := range t.Tags {
+		text += " +" + tag
+	}
+	return text
+}
+
+// match finds the tasks matching the query. Every word of the query has to match,
+// the tasks with the best match come first and equal ones keep their order
+func (p *picker) match() {
+	words := strings.Fields(p.query)
+	scores := make(map[int]int)
+	p.matches = p.matches[:0]
+	for i := range p.tasks {
+		text := pickerText(&p.tasks[i])
+		total, ok := 0, true
+		for _, w := range words {
+			score, matched := fuzzyMatch(w, text)
+			if !matched {
+				ok = false
+				break
+			}
+			total += score
+		}
+		if ok {
+			scores[i] = total
+			p.matches = append(p.matches, i)
+		}
+	}
+	sort.SliceStable(p.matches, func(a, b int) bool {
+		return scores[p.matches[a]] > scores[p.matches[b]]
+	})
+	p.cursor, p.offset = 0, 0
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in text in order,
+// ignoring the case. Matches of consecutive characters and at the start of words score higher
+func fuzzyMatch(pattern string, text string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	score, j, previous := 0, 0, -2
+	for i := 0; i < len(t) && j < len(p); i++ {
+		if t[i] != p[j] {
+			continue
+		}
+		score++
+		if i == previous+1 {
+			score += 5
+		}
+		if i == 0 || !unicode.IsLetter(t[i-1]) && !unicode.IsDigit(t[i-1]) {
+			score += 3
+		}
+		previous = i
+		j++
+	}
+	return score, j == len(p)
+}
+
+// handleKey changes the query, moves the cursor or selects tasks
+func (p *picker) handleKey(key string) {
+	switch key {
+	case keyEnter:
+		p.done = true
+		if len(p.chosen) == 0 && len(p.matches) > 0 {
+			p.chosen[p.tasks[p.matches[p.cursor]].Id] = true
+		}
+	case keyEscape, keyCtrlC:
+		p.cancelled = true
+	case keyDown, keyCtrlN:
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	case keyUp, keyCtrlP:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyTab:
+		if len(p.matches) == 0 {
+			return
+		}
+		id := p.tasks[p.matches[p.cursor]].Id
+		if p.chosen[id] {
+			delete(p.chosen, id)
+		} else {
+			p.chosen[id] = true
+		}
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	default:
+		if query, ok := editInput(p.query, key); ok {
+			p.query = query
+			p.match()
+		}
+	}
+}
+
+// ids returns the ids of the selected tasks in the order of the tasks
+func (p *picker) ids() idFlags {
+	var ids idFlags
+	for _, t := range p.tasks {
+		if p.chosen[t.Id] {
+			ids = append(ids, strconv.FormatInt(t.Id, 10))
+		}
+	}
+	return ids
+}
+
+// view returns the lines of the screen with the given size
+func (p *picker) view(width int, height int) []string {
+	if width <= 0 {
+		width = 80
+	}
+	if height < 4 {
+		height = 4
+	}
+
+	lines := []string{
+		paint(theme.Header, fitText(p.title, width, true)[0]),
+		"Search: " + p.query,
+	}
+
+	listHeight := height - 3
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+listHeight {
+		p.offset = p.cursor - listHeight + 1
+	}
+	for i := p.offset; i < p.offset+listHeight; i++ {
+		if i >= len(p.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		t := &p.tasks[p.matches[i]]
+		mark := "[ ]"
+		if p.chosen[t.Id] {
+			mark = "[x]"
+		}
+		cursor := "  "
+		if i == p.cursor {
+			cursor = "> "
+		}
+		line := layoutLines(fmt.Sprintf("%s%s %d ", cursor, mark, t.Id), t.Description, "", t.CategoryName, width, true)[0]
+		if i == p.cursor {
+			line = paint(theme.Selected, padRight(line, width))
+		}
+		lines = append(lines, line)
+	}
+
+	status := fmt.Sprintf("%d/%d tasks, %d selected - tab: select, enter: confirm, esc: cancel", len(p.matches), len(p.tasks), len(p.chosen))
+	return append(lines, fitText(status, width, true)[0])
+}
+
+// pick lets the user select some of the tasks on the terminal,
+// it returns no ids if the selection got cancelled
+func pick(title string, tasks []Task) (idFlags, error) {
+	p := newPicker(title, tasks)
+	err := interactive(func(tty io.Writer, keys <-chan string) {
+		for !p.done && !p.cancelled {
+			drawScreen(tty, p.view(terminalSize()))
+			key, ok := <-keys
+			if !ok {
+				p.cancelled = true
+				return
+			}
+			p.handleKey(key)
+		}
+	})
+	if err != nil || p.cancelled {
+		return nil, err
+	}
+	return p.ids(), nil
+}
+
+// canPick reports whether the tasks can be picked interactively
+func canPick() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// pickIds lets the user pick the tasks to run the action on from the tasks matching the filter
+// and exits if none get selected
+func pickIds(action string, filterExpr string) idFlags {
+	filter, err := ParseFilter(filterExpr)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	tasks := FilterTasks(filter, "id", "ASC")
+	if len(tasks) == 0 {
+		log.Fatalf("There are no tasks to %s\n", action)
+	}
+
+	ids, err := pick("Select the tasks to "+action, tasks)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if len(ids) == 0 {
+		log.Fatalln("No tasks selected")
+	}
+	return ids
+}
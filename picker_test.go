@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_fuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		want    bool
+	}{
+		{"", "Clean Room", true},
+		{"clr", "Clean Room", true},
+		{"CLEAN", "Clean Room", true},
+		{"rc", "Clean Room", false},
+		{"xyz", "Clean Room", false},
+	}
+	for _, tt := range tests {
+		if _, got := fuzzyMatch(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+
+	consecutive, _ := fuzzyMatch("room", "Clean Room")
+	scattered, _ := fuzzyMatch("room", "Read other mails")
+	if consecutive <= scattered {
+		t.Errorf("Expected consecutive matches to score higher, got %d and %d", consecutive, scattered)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	tasks := []Task{
+		{Id: 1, Description: "Clean Room", CategoryName: "home"},
+		{Id: 2, Description: "Add Tests", CategoryName: "coding", Tags: []string{"ci"}},
+		{Id: 3, Description: "Buy Present", CategoryName: "home"},
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+		want idFlags
+	}{
+		{"cursor", []string{keyEnter}, idFlags{"1"}},
+		{"query", []string{"p", "r", "e", "s", keyEnter}, idFlags{"3"}},
+		{"several words", []string{"h", "o", "m", " ", "b", keyEnter}, idFlags{"3"}},
+		{"tags", []string{"+", "c", "i", keyEnter}, idFlags{"2"}},
+		{"multi select", []string{keyTab, keyDown, keyTab, keyEnter}, idFlags{"1", "3"}},
+		{"unselect", []string{keyTab, keyUp, keyTab, keyTab, keyEnter}, idFlags{"2"}},
+		{"no match", []string{"x", "y", keyEnter}, nil},
+		{"backspace", []string{"x", keyBackspace, keyDown, keyEnter}, idFlags{"2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPicker("Select", tasks)
+			typeKeys(p, tt.keys...)
+			if !p.done {
+				t.Error("Expected enter to finish the selection")
+			}
+			if got := p.ids(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	p := newPicker("Select", tasks)
+	typeKeys(p, keyEscape)
+	if !p.cancelled {
+		t.Error("Expected escape to cancel the selection")
+	}
+}
+
+func TestPicker_view(t *testing.T) {
+	setColors(false)
+	defer setColors(true)
+
+	p := newPicker("Select the tasks to delete", []Task{
+		{Id: 1, Description: "Clean Room", CategoryName: "home"},
+		{Id: 12, Description: "Add Tests", CategoryName: "coding"},
+	})
+	typeKeys(p, keyTab)
+
+	want := []string{
+		"Select the tasks to delete",
+		"Search:",
+		"  [x] 1 Clean Room" + strings.Repeat(" ", 38) + "home",
+		"> [ ] 12 Add Tests" + strings.Repeat(" ", 36) + "coding",
+		"",
+	}
+	lines := p.view(60, 6)
+	for i := range want {
+		if got := strings.TrimRight(lines[i], " "); got != want[i] {
+			t.Errorf("Got line %q, want %q", got, want[i])
+		}
+	}
+	if !strings.HasPrefix(lines[5], "2/2 tasks, 1 selected") {
+		t.Errorf("Got status %q", lines[5])
+	}
+}
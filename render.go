@@ -1,56 +1,183 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
-	"github.com/gookit/color"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 )
 
-// RenderTableTasks renders the table with the tasks
-func RenderTableTasks(orderBy string, sorted string) {
+// formats the tasks can be rendered in
+const (
+	formatAligned  = "aligned"
+	formatTable    = "table"
+	formatJSON     = "json"
+	formatNDJSON   = "ndjson"
+	formatCSV      = "csv"
+	formatMarkdown = "markdown"
+	formatTemplate = "template"
+)
 
-	tasks := AllTasks(orderBy, sorted)
-	data := make([][]string, len(tasks))
+// validateFormat returns an error if the format does not exist
+func validateFormat(format string) error {
+	switch format {
+	case formatAligned, formatTable, formatJSON, formatNDJSON, formatCSV, formatMarkdown, formatTemplate:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %s, %s, %s, %s, %s, %s or %s", format,
+			formatAligned, formatTable, formatJSON, formatNDJSON, formatCSV, formatMarkdown, formatTemplate)
+	}
+}
+
+// Page describes which part of the tasks gets rendered.
+// A Limit of 0 renders all tasks
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// slice returns the bounds of the page within n tasks
+func (p Page) slice(n int) (int, int) {
+	start := p.Offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if p.Limit > 0 && start+p.Limit < n {
+		end = start + p.Limit
+	}
+	return start, end
+}
+
+// tableColumn is a column which can be shown in the table of tasks
+type tableColumn struct {
+	header string
+	// style returns the style of the column if the values are not styled already,
+	// tablewriter applies it after wrapping the values
+	style func(th *Theme) string
+	value func(t *Task) string
+}
+
+// defaultTableColumns are the columns of the table of tasks if no columns are given
+var defaultTableColumns = []string{"check", "id", "description", "until", "category"}
 
-	todo := 0
+// tableColumns are all columns which can be shown in the table of tasks
+var tableColumns = map[string]tableColumn{
+	"check": {"  ", nil, (*Task).getCheckBox},
+	"id": {"ID", nil, func(t *Task) string {
+		return paint(theme.Id, t.Id)
+	}},
+	"description": {"Description", func(th *Theme) string { return th.Description }, func(t *Task) string {
+		return t.Description
+	}},
+	"until": {"Until", nil, func(t *Task) string {
+		return timeUntil(t.Until)
+	}},
+	"category": {"Category", nil, func(t *Task) string {
+		return t.CategoryName
+	}},
+	"tags": {"Tags", nil, func(t *Task) string {
+		return strings.Join(t.Tags, " ")
+	}},
+	"priority": {"Pri", nil, func(t *Task) string {
+		return priorityLetter(t.Priority)
+	}},
+	"urgency": {"Urgency", nil, func(t *Task) string {
+		u, _ := config.Urgency.Urgency(t, time.Now())
+		return fmt.Sprintf("%.1f", u)
+	}},
+	"created": {"Created", nil, func(t *Task) string {
+		return convertDate(t.Created).Format("2006-01-02")
+	}},
+}
+
+// validateColumns returns an error if one of the columns does not exist
+func validateColumns(columns []string) error {
+	for _, name := range columns {
+		if _, ok := tableColumns[name]; !ok {
+			names := make([]string, 0, len(tableColumns))
+			for n := range tableColumns {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown column %q, expected one of %s", name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// RenderTableTasks renders the table with the tasks.
+// If no columns are given the defaultTableColumns are shown.
+// The descriptions get fitted into the width of the terminal if it is known
+func RenderTableTasks(orderBy string, sorted string, filter *Filter, page Page, columns []string) {
+
+	if len(columns) == 0 {
+		columns = defaultTableColumns
+	}
+
+	tasks := FilterTasksPage(filter, orderBy, sorted, page.Limit, page.Offset)
+	total, done := CountTasks(filter)
+	data := make([][]string, len(tasks))
 
 	for i := range data {
-		task := tasks[i]
+		data[i] = make([]string, len(columns))
+		for j, name := range columns {
+			data[i][j] = tableColumns[name].value(&tasks[i])
+		}
+	}
 
-		if !task.Done {
-			todo++
+	header := make([]string, len(columns))
+	headerColors := make([]tablewriter.Colors, len(columns))
+	columnColors := make([]tablewriter.Colors, len(columns))
+	for i, name := range columns {
+		c := tableColumns[name]
+		header[i] = c.header
+		if c.style != nil {
+			columnColors[i] = tableColors(c.style(&theme))
+		}
+		if name != "check" {
+			headerColors[i] = tableColors(theme.Header)
 		}
+	}
 
-		data[i] = task.StringArray()
+	footer := make([]string, len(columns))
+	footer[len(footer)-1] = strconv.Itoa(total - done)
+	if len(footer) > 1 {
+		footer[len(footer)-2] = "ToDo"
+	}
 
+	table := tablewriter.NewWriter(out)
+	table.SetHeader(header)
+	if !config.Compact {
+		table.SetFooter(footer)
+	}
+	if len(tasks) < total {
+		table.SetCaption(true, fmt.Sprintf("Showing %d-%d of %d tasks", page.Offset+1, page.Offset+len(tasks), total))
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"  ", "ID", "Description", "Until", "Category"})
-	table.SetFooter([]string{"", "", "", "ToDo", strconv.Itoa(todo)})
+	if colorsEnabled {
+		table.SetHeaderColor(headerColors...)
+		table.SetColumnColor(columnColors...)
+	}
 
-	table.SetHeaderColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{tablewriter.FgHiGreenColor},
-		tablewriter.Colors{tablewriter.FgHiGreenColor},
-		tablewriter.Colors{tablewriter.FgHiGreenColor},
-		tablewriter.Colors{tablewriter.FgHiGreenColor},
-	)
-	table.SetColumnColor(
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiBlackColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiWhiteColor},
-		tablewriter.Colors{tablewriter.FgHiWhiteColor},
-		tablewriter.Colors{tablewriter.FgHiWhiteColor},
-	)
+	for j, name := range columns {
+		if name == "description" && termWidth > 0 {
+			fitTableColumn(header, data, j, termWidth, config.Truncate)
+			table.SetAutoWrapText(false)
+		}
+	}
 
 	table.SetBorder(false)
 	table.AppendBulk(data)
-	fmt.Println()
+	if config.Compact {
+		table.SetHeaderLine(false)
+	} else {
+		fmt.Fprintln(out)
+	}
 	table.Render()
 }
 
@@ -63,7 +190,7 @@ func RenderTableCategories() {
 		data[i] = categories[i].StringArray()
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(out)
 	table.SetHeader([]string{"ID", "Name"})
 
 	for _, v := range data {
@@ -73,6 +200,22 @@ func RenderTableCategories() {
 	table.Render()
 }
 
+// RenderTableViews renders the table with all saved views
+func RenderTableViews(views []View) {
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Name", "Filter", "Order", "Renderer", "Columns"})
+
+	for _, v := range views {
+		order := v.OrderBy
+		if v.Desc {
+			order += " desc"
+		}
+		table.Append([]string{v.Name, v.Filter, order, v.Renderer, strings.Join(v.Columns, ",")})
+	}
+
+	table.Render()
+}
+
 // AlignedOutputCategory represents a category and all tasks with the given category
 // It also saves the amount of tasks for the category as well as the amount of tasks which
 // have been finished/marked as done
@@ -83,10 +226,28 @@ type AlignedOutputCategory struct {
 	Done     int
 }
 
-// RenderAligned renders the categories with its tasks out in the following format
-func RenderAligned(orderBy string, sorted string) {
+// RenderAligned renders the categories with its tasks out in the following format.
+// The page gets applied to the tasks of every category
+func RenderAligned(orderBy string, sorted string, filter *Filter, page Page) {
+
+	tasks := FilterTasks(filter, orderBy, sorted)
+	m := groupByCategory(tasks)
 
-	tasks := AllTasks(orderBy, sorted)
+	total, done := 0, 0
+	fmt.Fprintln(out)
+	for _, value := range sortCategories(m, config) {
+		t, d := value.Render(page)
+		fmt.Fprintln(out)
+		total += t
+		done += d
+	}
+
+	fmt.Fprintf(out, "%d left, %d done\n\n", total-done, done)
+
+}
+
+// groupByCategory groups the tasks by the name of their category
+func groupByCategory(tasks []Task) map[string]*AlignedOutputCategory {
 	m := make(map[string]*AlignedOutputCategory)
 
 	for i := range tasks {
@@ -110,34 +271,203 @@ func RenderAligned(orderBy string, sorted string) {
 		}
 	}
 
-	total, done := 0, 0
-	fmt.Println()
-	for _, value := range m {
-		t, d := value.Render()
-		fmt.Println()
-		total += t
-		done += d
+	return m
+}
+
+// sortCategories returns the categories in the order given by the config.
+// Categories which are equal regarding the order are sorted by name
+func sortCategories(m map[string]*AlignedOutputCategory, c Config) []*AlignedOutputCategory {
+	categories := make([]*AlignedOutputCategory, 0, len(m))
+	for _, a := range m {
+		categories = append(categories, a)
+	}
+
+	pinned := make(map[string]int)
+	for i, name := range c.PinnedCategories {
+		pinned[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	now := time.Now()
+	less := func(a, b *AlignedOutputCategory) bool {
+		switch c.CategoryOrder {
+		case categoryOrderUrgent:
+			ua, ub := a.mostUrgent(c.Urgency, now), b.mostUrgent(c.Urgency, now)
+			if ua != ub {
+				return ua > ub
+			}
+		case categoryOrderOpen:
+			oa, ob := a.total-a.Done, b.total-b.Done
+			if oa != ob {
+				return oa > ob
+			}
+		case categoryOrderPinned:
+			pa, aPinned := pinned[a.Category]
+			pb, bPinned := pinned[b.Category]
+			if aPinned != bPinned {
+				return aPinned
+			}
+			if aPinned && pa != pb {
+				return pa < pb
+			}
+		}
+		return a.Category < b.Category
 	}
 
-	fmt.Printf("%d left, %d done\n\n", total-done, done)
+	sort.Slice(categories, func(i, j int) bool {
+		return less(categories[i], categories[j])
+	})
+	return categories
+}
 
+// mostUrgent returns the highest urgency of the tasks of the category
+func (a *AlignedOutputCategory) mostUrgent(c UrgencyCoefficients, now time.Time) float64 {
+	max := 0.0
+	for i := range a.Tasks {
+		if u, _ := c.Urgency(&a.Tasks[i], now); u > max {
+			max = u
+		}
+	}
+	return max
 }
 
-// Render renders a single AlignedOutputCategory in the following format
+// Render renders the page of a single AlignedOutputCategory in the following format,
+// descriptions get fitted into the width of the terminal and due dates are aligned to the right
 // Default - [0/2]
-//        1. Clean House
-//        2. Clean Dishes
-func (a *AlignedOutputCategory) Render() (int, int) {
-	color.OpUnderscore.Printf("%s", strings.Title(a.Category))
-	fmt.Printf(" - [%d/%d]\n", a.Done, a.total)
-	for _, t := range a.Tasks {
-		d := t.Description
+//      ⨉  1 Clean House                        2d
+//      ⨉  2 Clean Dishes
+//         +3 more
+func (a *AlignedOutputCategory) Render(page Page) (int, int) {
+	fmt.Fprint(out, paint(theme.Category, strings.Title(a.Category)))
+	fmt.Fprintf(out, " - [%d/%d]\n", a.Done, a.total)
+	if config.CollapseDone && a.Done == a.total {
+		return a.total, a.Done
+	}
+	start, end := page.slice(len(a.Tasks))
+	for _, t := range a.Tasks[start:end] {
+		prefix := taskPrefix(&t)
+		style := ""
 		if t.Done {
-			d = color.OpStrikethrough.Sprint(d)
+			style = theme.Completed
+		}
+		due := ""
+		if t.Until != 0 {
+			due = timeUntil(t.Until)
+		}
+		for _, line := range layoutLines(prefix, t.Description, style, due, termWidth, config.Truncate) {
+			fmt.Fprintln(out, line)
 		}
-		fmt.Printf("%15s  %d %s\n", t.getCheckBox(), t.Id, d)
+	}
+	if more := len(a.Tasks) - end; more > 0 {
+		indent := 9
+		if config.Compact {
+			indent = 2
+		}
+		fmt.Fprintf(out, "%*s+%d more\n", indent, "", more)
 	}
 
 	return a.total, a.Done
 
 }
+
+// RenderSearchResults renders the results of a search with the matched words highlighted
+//      ✓  4 Clean my room  [home]
+//           …vacuum under the bed…
+func RenderSearchResults(results []SearchResult) {
+	fmt.Fprintln(out)
+	for _, r := range results {
+		fmt.Fprintf(out, "%s  %d %s  [%s]\n", padLeft(r.getCheckBox(), 6), r.Id, highlightMatches(r.Highlighted), r.CategoryName)
+		if r.Snippet != "" {
+			fmt.Fprintf(out, "%8s%s\n", "", highlightMatches(r.Snippet))
+		}
+	}
+	fmt.Fprintf(out, "\n%d results\n\n", len(results))
+}
+
+// RenderNext renders the tasks ranked by their urgency and the reasons behind it
+//  1. [12.4]  7 Pay invoice  (home)
+//          due in 2d +8.4, priority H +6.0
+func RenderNext(tasks []Task, c UrgencyCoefficients, now time.Time) {
+	fmt.Fprintln(out)
+	for i := range tasks {
+		t := &tasks[i]
+		score, reasons := c.Urgency(t, now)
+		fmt.Fprintf(out, "%3d. [%5.1f] %s %s  (%s)\n", i+1, score, paint(theme.Id, t.Id), t.Description, t.CategoryName)
+
+		explained := make([]string, len(reasons))
+		for j, r := range reasons {
+			explained[j] = r.String()
+		}
+		if len(explained) > 0 {
+			fmt.Fprintf(out, "%13s%s\n", "", paint(theme.Muted, strings.Join(explained, ", ")))
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+// csvHeader are the columns of the csv output, named like the fields of the JSON output
+var csvHeader = []string{"id", "description", "done", "created", "until", "category", "tags", "notes", "priority", "depends", "blocked"}
+
+// RenderCSV renders the tasks as csv according to RFC 4180 with a header row
+func RenderCSV(tasks []Task) error {
+	w := csv.NewWriter(out)
+	w.UseCRLF = true
+
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for i := range tasks {
+		t := newTaskJSON(&tasks[i])
+		until := ""
+		if t.Until != nil {
+			until = *t.Until
+		}
+		depends := make([]string, len(t.Depends))
+		for j, id := range t.Depends {
+			depends[j] = strconv.FormatInt(id, 10)
+		}
+		record := []string{
+			strconv.FormatInt(t.Id, 10),
+			t.Description,
+			strconv.FormatBool(t.Done),
+			t.Created,
+			until,
+			t.Category,
+			strings.Join(t.Tags, " "),
+			t.Notes,
+			t.Priority,
+			strings.Join(depends, " "),
+			strconv.FormatBool(t.Blocked),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// RenderMarkdown renders the tasks as GitHub flavored markdown checklists grouped by category
+// ## Home
+//
+// - [ ] 1 Clean Room (due 2026-10-21)
+// - [x] 3 Clean dishes
+func RenderMarkdown(tasks []Task) {
+	for i, a := range sortCategories(groupByCategory(tasks), config) {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "## %s\n\n", strings.Title(a.Category))
+		for _, t := range a.Tasks {
+			check := " "
+			if t.Done {
+				check = "x"
+			}
+			line := fmt.Sprintf("- [%s] %d %s", check, t.Id, strings.Join(strings.Fields(t.Description), " "))
+			if t.Until != 0 {
+				line += fmt.Sprintf(" (due %s)", convertDate(t.Until).Format("2006-01-02"))
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+}
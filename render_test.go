@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Not exactly sure how to write tests and test the rendering
@@ -13,7 +17,7 @@ import (
 func TestRenderAligned(t *testing.T) {
 	defer cleanDatabase()
 	createThreeTasks()
-	RenderAligned("id", "")
+	RenderAligned("id", "", nil, Page{})
 
 }
 
@@ -21,7 +25,7 @@ func TestRenderTableTasks(t *testing.T) {
 	defer cleanDatabase()
 	createThreeTasks()
 
-	RenderTableTasks("id", "")
+	RenderTableTasks("id", "", nil, Page{}, nil)
 }
 
 func TestRenderTableCategories(t *testing.T) {
@@ -29,4 +33,142 @@ func TestRenderTableCategories(t *testing.T) {
 	createThreeTasks()
 
 	RenderTableCategories()
-}
\ No newline at end of file
+}
+
+func TestRenderAlignedPage(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	RenderAligned("id", "ASC", nil, Page{Limit: 1})
+
+	got := buf.String()
+	if !strings.Contains(got, "+1 more") {
+		t.Errorf("Expected +1 more for the category home in %q", got)
+	}
+	if strings.Contains(got, "Buy Present") {
+		t.Errorf("Expected Buy Present to be hidden in %q", got)
+	}
+	if !strings.Contains(got, "3 left, 0 done") {
+		t.Errorf("Expected the summary to count hidden tasks in %q", got)
+	}
+}
+
+func TestPage_slice(t *testing.T) {
+	tests := []struct {
+		page      Page
+		n         int
+		wantStart int
+		wantEnd   int
+	}{
+		{Page{}, 5, 0, 5},
+		{Page{Limit: 2}, 5, 0, 2},
+		{Page{Limit: 2, Offset: 4}, 5, 4, 5},
+		{Page{Limit: 2, Offset: 7}, 5, 5, 5},
+	}
+	for _, tt := range tests {
+		start, end := tt.page.slice(tt.n)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("Page.slice() = %d, %d, want %d, %d", start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func Test_sortCategories(t *testing.T) {
+	now := time.Now().Unix()
+	m := map[string]*AlignedOutputCategory{
+		"home":   {total: 2, Done: 1, Category: "home", Tasks: []Task{{Until: now + 500}, {Done: true, Until: now + 10}}},
+		"coding": {total: 1, Done: 1, Category: "coding", Tasks: []Task{{Done: true}}},
+		"work":   {total: 3, Done: 0, Category: "work", Tasks: []Task{{Until: now + 100}, {}, {}}},
+	}
+	tests := []struct {
+		order  string
+		pinned []string
+		want   []string
+	}{
+		{categoryOrderAlpha, nil, []string{"coding", "home", "work"}},
+		{categoryOrderUrgent, nil, []string{"work", "home", "coding"}},
+		{categoryOrderOpen, nil, []string{"work", "home", "coding"}},
+		{categoryOrderPinned, []string{"Home", "coding"}, []string{"home", "coding", "work"}},
+		{categoryOrderPinned, nil, []string{"coding", "home", "work"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			got := sortCategories(m, Config{CategoryOrder: tt.order, PinnedCategories: tt.pinned, Urgency: defaultUrgencyCoefficients()})
+			for i := range got {
+				if got[i].Category != tt.want[i] {
+					t.Errorf("Got %s at position %d, expected %s", got[i].Category, i, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderAlignedCollapseDone(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	TaskDone([]string{"2"})
+
+	var buf bytes.Buffer
+	out = &buf
+	config.CollapseDone = true
+	defer func() {
+		out = os.Stdout
+		config = defaultConfig()
+	}()
+
+	RenderAligned("id", "ASC", nil, Page{})
+
+	got := buf.String()
+	if strings.Contains(got, "Add Tests") {
+		t.Errorf("Expected the finished category coding to be collapsed in %q", got)
+	}
+	if !strings.Contains(got, "Clean Room") {
+		t.Errorf("Expected the open category home to be shown in %q", got)
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	tasks := []Task{
+		{Id: 1, Description: "Buy milk, eggs and \"good\" bread", CategoryName: "home", Tags: []string{"shop"}},
+		{Id: 2, Description: "Add Tests", Done: true, CategoryName: "coding", Priority: PriorityHigh},
+	}
+	if err := RenderCSV(tasks); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(buf.String(), "\r\n")
+	if lines[0] != "id,description,done,created,until,category,tags,notes,priority,depends,blocked" {
+		t.Errorf("Got header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `1,"Buy milk, eggs and ""good"" bread",false,`) {
+		t.Errorf("Expected the description to be quoted in %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], ",coding,,,H,,false") {
+		t.Errorf("Got %q", lines[2])
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	RenderMarkdown([]Task{
+		{Id: 1, Description: "Clean Room", CategoryName: "home"},
+		{Id: 2, Description: "Add Tests", CategoryName: "coding"},
+		{Id: 3, Description: "Clean dishes", Done: true, CategoryName: "home"},
+	})
+
+	want := "## Coding\n\n- [ ] 2 Add Tests\n\n## Home\n\n- [ ] 1 Clean Room\n- [x] 3 Clean dishes\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scanTag is the tag of the tasks created by scan, they also get the keyword of their comment as tag
+const scanTag = "code"
+
+// scanMaxSize is the size in bytes above which files are not scanned as they are most likely generated
+const scanMaxSize = 1 << 20
+
+// scanPattern returns a pattern matching a TODO, FIXME or HACK comment like "// TODO(anna): handle the timeout"
+// with the keyword and the text of the comment, the comment starts with one of the markers
+func scanPattern(markers string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^:\w"'])(?:` + markers + `)\s*(TODO|FIXME|HACK)\b(?:\([^)]*\))?:?\s*(.*)$`)
+}
+
+// the comment patterns of files by their comment markers. # and * also start the headings and bullets of markdown,
+// so they only count in files of languages with comments starting with # or block comments continued by *
+var (
+	scanComment         = scanPattern(`//+|/\*+|--|;+|%+|<!--`)
+	scanCommentHash     = scanPattern(`//+|/\*+|--|;+|%+|<!--|#+`)
+	scanCommentStar     = scanPattern(`//+|/\*+|\*+|--|;+|%+|<!--`)
+	scanCommentHashStar = scanPattern(`//+|/\*+|\*+|--|;+|%+|<!--|#+`)
+)
+
+// scanHashFiles are the extensions and names of files with comments starting with #
+var scanHashFiles = map[string]bool{
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true, ".py": true, ".rb": true, ".pl": true, ".pm": true,
+	".r": true, ".jl": true, ".ex": true, ".exs": true, ".nim": true, ".cr": true, ".coffee": true, ".ps1": true,
+	".yaml": true, ".yml": true, ".toml": true, ".conf": true, ".cfg": true, ".ini": true, ".tf": true, ".nix": true,
+	".cmake": true, ".mk": true, ".php": true,
+	"makefile": true, "dockerfile": true, "cmakelists.txt": true, "gemfile": true, "rakefile": true,
+}
+
+// scanStarFiles are the extensions of files with block comments whose lines may start with *
+var scanStarFiles = map[string]bool{
+	".go": true, ".c": true, ".h": true, ".cc": true, ".cpp": true, ".cxx": true, ".hpp": true, ".m": true, ".mm": true,
+	".java": true, ".kt": true, ".kts": true, ".scala": true, ".groovy": true, ".cs": true, ".swift": true, ".rs": true,
+	".dart": true, ".js": true, ".jsx": true, ".mjs": true, ".ts": true, ".tsx": true, ".css": true, ".scss": true,
+	".less": true, ".sql": true, ".proto": true, ".php": true,
+}
+
+// scanCommentOf returns the comment pattern of the file with the slash separated path
+func scanCommentOf(rel string) *regexp.Regexp {
+	name := strings.ToLower(path.Base(rel))
+	ext := path.Ext(name)
+	hash, star := scanHashFiles[ext] || scanHashFiles[name], scanStarFiles[ext]
+	switch {
+	case hash && star:
+		return scanCommentHashStar
+	case hash:
+		return scanCommentHash
+	case star:
+		return scanCommentStar
+	}
+	return scanComment
+}
+
+// CodeComment is a TODO, FIXME or HACK comment found by scan
+type CodeComment struct {
+	// File is the slash separated path relative to the repository
+	File    string
+	Line    int
+	Keyword string
+	Text    string
+}
+
+// location returns the location of the comment like main.go:42, it is the note of its task
+func (c *CodeComment) location() string {
+	return c.File + ":" + strconv.Itoa(c.Line)
+}
+
+// description returns the description of the task of the comment, the keyword if the comment has no text
+func (c *CodeComment) description() string {
+	if c.Text == "" {
+		return c.Keyword
+	}
+	return c.Text
+}
+
+// ScanResult counts what scan found and changed
+type ScanResult struct {
+	Repository string
+	Files      int
+	Comments   int
+	Added      int
+	Updated    int
+	Done       int
+}
+
+// ignoreRule is a pattern of a .gitignore file
+type ignoreRule struct {
+	// base is the directory of the .gitignore file relative to the repository, empty for the root
+	base    string
+	pattern []string
+	negate  bool
+	dirOnly bool
+	// anchored patterns contain a slash and match the path relative to base, the others any name
+	anchored bool
+}
+
+// parseIgnore parses the content of the .gitignore file in the directory base
+func parseIgnore(base string, content string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r ")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if line[0] == '!' {
+			r.negate, line = true, line[1:]
+		} else if line[0] == '\\' {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		if line = strings.TrimPrefix(line, "/"); line == "" {
+			continue
+		}
+		r.pattern = strings.Split(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// readIgnore reads the rules of the ignore file at path, a missing file has none
+func readIgnore(path string, base string) []ignoreRule {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parseIgnore(base, string(data))
+}
+
+// match reports whether the rule matches the slash separated path relative to the repository
+func (r *ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	segments := strings.Split(rel, "/")
+	if !r.anchored {
+		segments = segments[len(segments)-1:]
+	}
+	return matchSegments(r.pattern, segments)
+}
+
+// matchSegments matches the segments of a path against the ones of a pattern, ** matches any number of segments
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
+
+// isIgnored reports whether the path is ignored, the last matching rule decides
+func isIgnored(rules []ignoreRule, rel string, isDir bool) bool {
+	ignored := false
+	for i := range rules {
+		if rules[i].match(rel, isDir) {
+			ignored = !rules[i].negate
+		}
+	}
+	return ignored
+}
+
+// repositoryRoot returns the directory containing .git which dir is in, or dir if it is in no repository
+func repositoryRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// scanFile returns the comments of the file at path, binary and large files have none
+func scanFile(path string, rel string) ([]CodeComment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil || len(data) > scanMaxSize {
+		return nil, err
+	}
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil
+	}
+
+	var comments []CodeComment
+	pattern := scanCommentOf(rel)
+	for i, line := range strings.Split(string(data), "\n") {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		text := strings.TrimSpace(m[2])
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+		comments = append(comments, CodeComment{File: rel, Line: i + 1, Keyword: m[1], Text: text})
+	}
+	return comments, nil
+}
+
+// ScanComments returns the comments in the files below dir which is in the repository at root
+// and the amount of scanned files. Files ignored by the .gitignore files or .git/info/exclude are skipped
+func ScanComments(root string, dir string) ([]CodeComment, int, error) {
+	relative := func(p string) string {
+		rel, _ := filepath.Rel(root, p)
+		if rel == "." {
+			return ""
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	rules := readIgnore(filepath.Join(root, ".git", "info", "exclude"), "")
+	// the .gitignore files of the directories above dir apply to it as well
+	if prefix := relative(dir); prefix != "" {
+		rules = append(rules, readIgnore(filepath.Join(root, ".gitignore"), "")...)
+		segments := strings.Split(prefix, "/")
+		for i := 1; i < len(segments); i++ {
+			base := strings.Join(segments[:i], "/")
+			rules = append(rules, readIgnore(filepath.Join(root, filepath.FromSlash(base), ".gitignore"), base)...)
+		}
+	}
+
+	var comments []CodeComment
+	files := 0
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := relative(p)
+		if info.IsDir() {
+			if info.Name() == ".git" || p != dir && isIgnored(rules, rel, true) {
+				return filepath.SkipDir
+			}
+			rules = append(rules, readIgnore(filepath.Join(p, ".gitignore"), rel)...)
+			return nil
+		}
+		if !info.Mode().IsRegular() || isIgnored(rules, rel, false) {
+			return nil
+		}
+		files++
+		found, err := scanFile(p, rel)
+		comments = append(comments, found...)
+		return err
+	})
+	return comments, files, err
+}
+
+// SyncComments creates a task in the category for every comment without one, its location is the note.
+// Descriptions are unique, so a comment whose text is the description of another task gets its file appended, see scanDescription.
+// Tasks are matched by file and text, so they survive moved lines. Tasks of comments which are
+// gone get marked as done, only tasks of files below prefix are considered as only those were scanned
+func SyncComments(category string, prefix string, comments []CodeComment) (ScanResult, error) {
+	result := ScanResult{Repository: category, Comments: len(comments)}
+	key := func(file string, description string) string {
+		return file + "\x00" + scanText(description, file)
+	}
+
+	// tasks holds the tasks of every comment in the order they were created
+	tasks := make(map[string][]Task)
+	for _, t := range AllTasks("id", "ASC") {
+		if t.CategoryName != strings.ToLower(category) || !containsTag(t.Tags, scanTag) {
+			continue
+		}
+		location := strings.SplitN(t.Notes, "\n", 2)[0]
+		i := strings.LastIndex(location, ":")
+		if i < 0 {
+			continue
+		}
+		file := location[:i]
+		if prefix == "" || file == prefix || strings.HasPrefix(file, prefix+"/") {
+			tasks[key(file, t.Description)] = append(tasks[key(file, t.Description)], t)
+		}
+	}
+
+	for _, c := range comments {
+		k := key(c.File, c.description())
+		if len(tasks[k]) == 0 {
+			if err := addCommentTask(category, &c); err != nil {
+				return result, err
+			}
+			result.Added++
+			continue
+		}
+		t := tasks[k][0]
+		tasks[k] = tasks[k][1:]
+		ids := idFlags{strconv.FormatInt(t.Id, 10)}
+		if t.Notes != c.location() || t.Done {
+			SetNotes(ids, c.location())
+			result.Updated++
+		}
+		if t.Done {
+			// the comment came back
+			TaskUndone(ids)
+		}
+	}
+
+	for _, gone := range tasks {
+		for _, t := range gone {
+			if !t.Done {
+				TaskDone(idFlags{strconv.FormatInt(t.Id, 10)})
+				result.Done++
+			}
+		}
+	}
+	return result, nil
+}
+
+// scanDescription returns the nth description tried for the task of the comment. The first is its description,
+// the second has the file appended like "handle the error (main.go)" and the following a number like "(main.go 2)"
+func scanDescription(c *CodeComment, n int) string {
+	switch n {
+	case 0:
+		return c.description()
+	case 1:
+		return fmt.Sprintf("%s (%s)", c.description(), c.File)
+	}
+	return fmt.Sprintf("%s (%s %d)", c.description(), c.File, n)
+}
+
+// scanText returns the description of a task of a comment in the file without what scanDescription appended
+func scanText(description string, file string) string {
+	i := strings.LastIndex(description, " ("+file)
+	if i < 0 || !strings.HasSuffix(description, ")") {
+		return description
+	}
+	suffix := description[i+len(file)+2 : len(description)-1]
+	if _, err := strconv.Atoi(strings.TrimPrefix(suffix, " ")); suffix == "" || strings.HasPrefix(suffix, " ") && err == nil {
+		return description[:i]
+	}
+	return description
+}
+
+// addCommentTask saves the task of the comment with the first description of scanDescription which is not taken yet
+func addCommentTask(category string, c *CodeComment) error {
+	for n := 0; ; n++ {
+		t, err := SaveTask(category, scanDescription(c, n), -1, -1, scanTag, strings.ToLower(c.Keyword))
+		if err != nil {
+			return err
+		}
+		if t.Id != 0 {
+			SetNotes(idFlags{strconv.FormatInt(t.Id, 10)}, c.location())
+			return nil
+		}
+	}
+}
+
+// containsTag reports whether tags contains tag
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// scanCommand scans the directory given as argument or the current one for comments
+// and updates their tasks, with -dryrun it only lists the comments
+func scanCommand(args []string) {
+	if len(args) > 1 {
+		log.Fatalf("Expected at most one directory instead of %s\n", strings.Join(args, " "))
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		log.Fatalf("%s is no directory\n", dir)
+	}
+
+	root := repositoryRoot(dir)
+	comments, files, err := ScanComments(root, dir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if dryRun {
+		for _, c := range comments {
+			fmt.Fprintf(out, "%s %s %s\n", c.location(), c.Keyword, c.Text)
+		}
+		fmt.Fprintf(out, "Found %d comments in %d files, nothing was written\n", len(comments), files)
+		return
+	}
+	prefix, _ := filepath.Rel(root, dir)
+	if prefix == "." {
+		prefix = ""
+	}
+	result, err := SyncComments(filepath.Base(root), filepath.ToSlash(prefix), comments)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	result.Files = files
+	fmt.Fprintln(out, result)
+}
+
+// String summarises the result like "Found 5 comments in 42 files, added 3 tasks, updated 1 and marked 2 as done in gtask"
+func (r ScanResult) String() string {
+	return fmt.Sprintf("Found %d comments in %d files, added %d tasks, updated %d and marked %d as done in %s",
+		r.Comments, r.Files, r.Added, r.Updated, r.Done, strings.ToLower(r.Repository))
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_isIgnored(t *testing.T) {
+	rules := parseIgnore("", "# build output\n*.log\n/bin/\ndocs/**/*.html\n!keep.log\n\\#notes\n")
+	rules = append(rules, parseIgnore("web", "node_modules/\n/dist\n")...)
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"sub/app.log", false, true},
+		{"sub/keep.log", false, false},
+		{"bin", true, true},
+		{"bin", false, false},
+		{"sub/bin", true, false},
+		{"docs/index.html", false, true},
+		{"docs/api/v1/index.html", false, true},
+		{"index.html", false, false},
+		{"#notes", false, true},
+		{"web/node_modules", true, true},
+		{"web/lib/node_modules", true, true},
+		{"node_modules", true, false},
+		{"web/dist", true, true},
+		{"web/lib/dist", true, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := isIgnored(rules, tt.path, tt.isDir); got != tt.ignored {
+				t.Errorf("isIgnored() = %v, want %v", got, tt.ignored)
+			}
+		})
+	}
+}
+
+// writeFiles writes the files given by their slash separated path below dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanComments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	repo := filepath.Join(dir, "shop")
+	writeFiles(t, repo, map[string]string{
+		".git/HEAD":        "// TODO: inside git",
+		".gitignore":       "vendor/\n*.min.js\n",
+		"main.go":          "package main\n\n// TODO: handle the timeout\nfunc main() {\n\turl := \"http://example.com\" // FIXME(anna) use the config\n}\n",
+		"lib/db.py":        "# HACK\nquery = 'select 1'  # TODO retry on failure\n",
+		"lib/.gitignore":   "generated.py\n",
+		"lib/generated.py": "# TODO: generated\n",
+		"web/app.css":      "/* TODO: dark theme */\n",
+		"web/app.min.js":   "// TODO: minified\n",
+		"vendor/x.go":      "// TODO: vendored\n",
+		"notes.txt":        "Mention a TODO in prose\n",
+		"README.md":        "# TODO list\n* TODO write it\n<!-- TODO: link the docs -->\n",
+		"lib/cache.go":     "/*\n * TODO: expire entries\n */\n",
+		"image.png":        "\x00\x01// TODO: binary",
+	})
+
+	if got := repositoryRoot(filepath.Join(repo, "lib")); got != repo {
+		t.Errorf("Got the root %s, want %s", got, repo)
+	}
+	comments, files, err := ScanComments(repo, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CodeComment{
+		{"README.md", 3, "TODO", "link the docs"},
+		{"lib/cache.go", 2, "TODO", "expire entries"},
+		{"lib/db.py", 1, "HACK", ""},
+		{"lib/db.py", 2, "TODO", "retry on failure"},
+		{"main.go", 3, "TODO", "handle the timeout"},
+		{"main.go", 5, "FIXME", "use the config"},
+		{"web/app.css", 1, "TODO", "dark theme"},
+	}
+	if !reflect.DeepEqual(comments, want) {
+		t.Errorf("Got %+v, want %+v", comments, want)
+	}
+	if files != 9 {
+		t.Errorf("Got %d scanned files, want 9", files)
+	}
+
+	comments, _, err = ScanComments(repo, filepath.Join(repo, "lib"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 3 {
+		t.Errorf("Got %+v, expected the comments of lib without the ignored file", comments)
+	}
+}
+
+func TestSyncComments(t *testing.T) {
+	defer cleanDatabase()
+	comments := []CodeComment{
+		{"main.go", 3, "TODO", "handle the timeout"},
+		{"main.go", 5, "FIXME", "use the config"},
+		{"lib/db.py", 1, "HACK", ""},
+	}
+	result := syncComments(t, "Shop", "", comments)
+	if result.Added != 3 || result.Updated != 0 || result.Done != 0 {
+		t.Errorf("Got %+v, expected 3 added tasks", result)
+	}
+	tasks := AllTasks("id", "ASC")
+	if len(tasks) != 3 || tasks[0].CategoryName != "shop" || tasks[0].Notes != "main.go:3" ||
+		!reflect.DeepEqual(tasks[1].Tags, []string{"code", "fixme"}) || tasks[2].Description != "HACK" {
+		t.Fatalf("Got %+v", tasks)
+	}
+
+	// a line got inserted above the first comment and the second one got fixed
+	result = syncComments(t, "shop", "", []CodeComment{{"main.go", 4, "TODO", "handle the timeout"}, {"lib/db.py", 1, "HACK", ""}})
+	if result.Added != 0 || result.Updated != 1 || result.Done != 1 {
+		t.Errorf("Got %+v, expected 1 updated and 1 done task", result)
+	}
+	tasks = AllTasks("id", "ASC")
+	if tasks[0].Notes != "main.go:4" || tasks[0].Done || !tasks[1].Done || tasks[2].Done {
+		t.Errorf("Got %+v", tasks)
+	}
+
+	// scanning lib only leaves the tasks of main.go alone, the comment coming back reopens its task
+	result = syncComments(t, "shop", "lib", []CodeComment{{"lib/db.py", 2, "HACK", ""}})
+	if result.Updated != 1 || result.Done != 0 {
+		t.Errorf("Got %+v, expected only the task of lib to be updated", result)
+	}
+	result = syncComments(t, "shop", "", []CodeComment{{"main.go", 4, "TODO", "handle the timeout"}, {"main.go", 5, "FIXME", "use the config"}})
+	if result.Updated != 1 || result.Done != 1 || len(AllTasks("id", "ASC")) != 3 {
+		t.Errorf("Got %+v, expected the fixed task to be reopened", result)
+	}
+	if tasks = AllTasks("id", "ASC"); tasks[1].Done || !tasks[2].Done {
+		t.Errorf("Got %+v", tasks)
+	}
+}
+
+func TestSyncComments_duplicates(t *testing.T) {
+	defer cleanDatabase()
+	SaveTask("Home", "handle the error", 0, 0)
+	comments := []CodeComment{
+		{"main.go", 3, "TODO", "handle the error"},
+		{"lib/db.go", 1, "HACK", ""},
+		{"main.go", 8, "HACK", ""},
+		{"main.go", 9, "TODO", "handle the error"},
+		{"main.go", 12, "FIXME", "handle the error"},
+	}
+	result := syncComments(t, "shop", "", comments)
+	if result.Added != 5 {
+		t.Errorf("Got %+v, expected 5 added tasks", result)
+	}
+	tasks := AllTasks("id", "ASC")
+	want := []string{"handle the error", "handle the error (main.go)", "HACK", "HACK (main.go)",
+		"handle the error (main.go 2)", "handle the error (main.go 3)"}
+	if len(tasks) != len(want) {
+		t.Fatalf("Got %+v, want the descriptions %q", tasks, want)
+	}
+	for i := range want {
+		if tasks[i].Description != want[i] {
+			t.Fatalf("Got %+v, want the descriptions %q", tasks, want)
+		}
+	}
+	if tasks[1].Notes != "main.go:3" || tasks[3].Notes != "main.go:8" || tasks[5].Notes != "main.go:12" {
+		t.Errorf("Got %+v, expected the notes to be the locations", tasks)
+	}
+
+	// scanning again finds the tasks with the file in their description, also after the lines moved
+	for i := range comments {
+		comments[i].Line++
+	}
+	result = syncComments(t, "shop", "", comments)
+	if result.Added != 0 || result.Updated != 5 || result.Done != 0 {
+		t.Errorf("Got %+v, expected the 5 tasks to be updated", result)
+	}
+
+	// the same comment in another repository
+	result = syncComments(t, "admin", "", comments[:1])
+	if result.Added != 1 || len(AllTasks("id", "ASC")) != 7 || AllTasks("id", "ASC")[6].Description != "handle the error (main.go 4)" {
+		t.Errorf("Got %+v and %+v, expected a new task", result, AllTasks("id", "ASC"))
+	}
+}
+
+func Test_scanText(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"handle the error", "handle the error"},
+		{"handle the error (main.go)", "handle the error"},
+		{"handle the error (main.go 12)", "handle the error"},
+		{"handle the error (main.go later)", "handle the error (main.go later)"},
+		{"handle the error (lib/main.go)", "handle the error (lib/main.go)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := scanText(tt.description, "main.go"); got != tt.want {
+				t.Errorf("scanText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// syncComments runs SyncComments and fails the test on errors
+func syncComments(t *testing.T, category string, prefix string, comments []CodeComment) ScanResult {
+	result, err := SyncComments(category, prefix, comments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// markers which surround the matched words in the results of the search index,
+// they get replaced by colours when rendering
+const (
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+)
+
+// SearchResult represents a task matching a search
+// together with its highlighted description and notes
+type SearchResult struct {
+	Task
+	Rank        float64
+	Highlighted string
+	Snippet     string
+}
+
+// searchEngine is the sqlite full text search module used by the search index.
+// FTS5 is only available if go-sqlite3 got built with the sqlite_fts5 tag,
+// otherwise we fall back to FTS4
+var searchEngine string
+
+// createSearchIndex creates the full text search index over the descriptions
+// and notes of the tasks and the triggers which keep it in sync
+func createSearchIndex() {
+	searchEngine = ""
+	row := db.QueryRow(`SELECT sql FROM sqlite_master WHERE type='table' AND name='tasks_fts';`)
+	var existing string
+	switch err := row.Scan(&existing); err {
+	case nil:
+		searchEngine = "fts4"
+		if strings.Contains(strings.ToLower(existing), "fts5") {
+			searchEngine = "fts5"
+		}
+	case sql.ErrNoRows:
+	default:
+		log.Fatal(err)
+	}
+
+	if searchEngine == "fts5" && !fts5Available() {
+		// the database was created by a build with FTS5, its triggers would make every write of the tasks fail
+		if err := dropFts5Index(); err != nil {
+			log.Fatalln("Could not replace the FTS5 search index:", err)
+		}
+		searchEngine = ""
+	}
+
+	if searchEngine == "" {
+		fts5 := `CREATE VIRTUAL TABLE tasks_fts USING fts5(description, notes, content='tasks', content_rowid='id', tokenize='unicode61');`
+		fts4 := `CREATE VIRTUAL TABLE tasks_fts USING fts4(content="tasks", description, notes, tokenize=unicode61);`
+		if _, err := db.Exec(fts5); err == nil {
+			searchEngine = "fts5"
+		} else if _, err := db.Exec(fts4); err == nil {
+			searchEngine = "fts4"
+		} else {
+			checkErrorQueries(err, fts4)
+			return
+		}
+		// index the tasks which existed before the index
+		rebuild := `INSERT INTO tasks_fts(tasks_fts) VALUES('rebuild');`
+		_, err := db.Exec(rebuild)
+		checkErrorQueries(err, rebuild)
+	}
+
+	for _, trigger := range searchTriggers() {
+		_, err := db.Exec(trigger)
+		checkErrorQueries(err, trigger)
+	}
+}
+
+// fts5Available reports whether sqlite got compiled with FTS5
+func fts5Available() bool {
+	var used bool
+	err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5');`).Scan(&used)
+	return err == nil && used
+}
+
+// dropFts5Index removes the FTS5 search index and its triggers without the fts5 module.
+// SQLite can not drop a virtual table whose module is missing, so its entry gets deleted from the schema
+// on a connection of its own. The open connections still know the index and get closed
+func dropFts5Index() error {
+	var seq int
+	var name, file string
+	if err := db.QueryRow("PRAGMA database_list;").Scan(&seq, &name, &file); err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf("the database has no file")
+	}
+	schema, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return err
+	}
+	defer schema.Close()
+	schema.SetMaxOpenConns(1)
+
+	var version int
+	if err := schema.QueryRow("PRAGMA schema_version;").Scan(&version); err != nil {
+		return err
+	}
+	var statements []string
+	for _, trigger := range []string{"insert", "delete", "update_before", "update_after"} {
+		statements = append(statements, fmt.Sprintf("DROP TRIGGER IF EXISTS tasks_fts_%s;", trigger))
+	}
+	statements = append(statements,
+		"PRAGMA writable_schema=ON;",
+		"DELETE FROM sqlite_master WHERE type='table' AND name='tasks_fts';",
+		"PRAGMA writable_schema=OFF;",
+		fmt.Sprintf("PRAGMA schema_version=%d;", version+1),
+	)
+	for _, shadow := range []string{"data", "idx", "content", "docsize", "config"} {
+		statements = append(statements, fmt.Sprintf("DROP TABLE IF EXISTS tasks_fts_%s;", shadow))
+	}
+	for _, stmt := range statements {
+		if _, err := schema.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	if pool, ok := db.(*sql.DB); ok {
+		// 2 is the default of database/sql
+		pool.SetMaxIdleConns(0)
+		pool.SetMaxIdleConns(2)
+	}
+	return nil
+}
+
+// searchTriggers returns the triggers keeping the search index
+// in sync with the tasks table
+func searchTriggers() []string {
+	insert := `INSERT INTO tasks_fts(rowid, description, notes) VALUES (new.id, new.description, new.notes);`
+	remove := `INSERT INTO tasks_fts(tasks_fts, rowid, description, notes) VALUES ('delete', old.id, old.description, old.notes);`
+	removeWhen := "AFTER"
+	if searchEngine == "fts4" {
+		remove = `DELETE FROM tasks_fts WHERE docid=old.id;`
+		removeWhen = "BEFORE"
+	}
+
+	return []string{
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON tasks BEGIN %s END;`, insert),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS tasks_fts_delete %s DELETE ON tasks BEGIN %s END;`, removeWhen, remove),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS tasks_fts_update_before %s UPDATE OF description, notes ON tasks BEGIN %s END;`, removeWhen, remove),
+		fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS tasks_fts_update_after AFTER UPDATE OF description, notes ON tasks BEGIN %s END;`, insert),
+	}
+}
+
+// searchQuery converts the terms given by the user into a query for the search index.
+// Quoted phrases ("clean room"), prefixes (inv*) and the operators AND, OR and NOT
+// are kept, every other word is quoted so it can not break the query syntax
+func searchQuery(terms string) (string, error) {
+	var parts []string
+	for len(terms) > 0 {
+		terms = strings.TrimLeftFunc(terms, unicode.IsSpace)
+		if terms == "" {
+			break
+		}
+
+		if terms[0] == '"' {
+			end := strings.IndexByte(terms[1:], '"')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated phrase %s", terms)
+			}
+			phrase := strings.Join(searchWords(terms[1:end+1]), " ")
+			if phrase != "" {
+				parts = append(parts, `"`+phrase+`"`)
+			}
+			terms = terms[end+2:]
+			continue
+		}
+
+		end := strings.IndexFunc(terms, unicode.IsSpace)
+		if end < 0 {
+			end = len(terms)
+		}
+		word := terms[:end]
+		terms = terms[end:]
+
+		switch {
+		case word == "AND" || word == "OR" || word == "NOT":
+			parts = append(parts, word)
+		case strings.HasSuffix(word, "*"):
+			words := searchWords(word)
+			if len(words) == 0 {
+				return "", fmt.Errorf("prefix %s contains no letters or digits", word)
+			}
+			last := len(words) - 1
+			if last > 0 {
+				parts = append(parts, `"`+strings.Join(words[:last], " ")+`"`)
+			}
+			parts = append(parts, words[last]+"*")
+		default:
+			if words := searchWords(word); len(words) > 0 {
+				parts = append(parts, `"`+strings.Join(words, " ")+`"`)
+			}
+		}
+	}
+
+	query := strings.Join(parts, " ")
+	if query == "" {
+		return "", fmt.Errorf("nothing to search for")
+	}
+	return query, nil
+}
+
+// searchWords splits s into the words the tokenizer of the search index sees
+func searchWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// SearchTasks returns all tasks whose description or notes match the terms,
+// the best matches come first
+func SearchTasks(terms string) ([]SearchResult, error) {
+	if searchEngine == "" {
+		return nil, fmt.Errorf("the search index is not available")
+	}
+	query, err := searchQuery(terms)
+	if err != nil {
+		return nil, err
+	}
+
+	var sqlStmt string
+	if searchEngine == "fts5" {
+		sqlStmt = fmt.Sprintf(`SELECT %[3]s,
+			highlight(tasks_fts, 0, '%[1]s', '%[2]s'), snippet(tasks_fts, 1, '%[1]s', '%[2]s', '…', 12), -bm25(tasks_fts)
+			FROM tasks_fts INNER JOIN tasks as t ON (t.id=tasks_fts.rowid) INNER JOIN categories As c ON (t.category_id=c.id)
+			WHERE tasks_fts MATCH ? ORDER BY bm25(tasks_fts);`, matchStart, matchEnd, taskColumns)
+	} else {
+		sqlStmt = fmt.Sprintf(`SELECT %[3]s,
+			offsets(tasks_fts), snippet(tasks_fts, '%[1]s', '%[2]s', '…', 1, 12), matchinfo(tasks_fts, 'pcnx')
+			FROM tasks_fts INNER JOIN tasks as t ON (t.id=tasks_fts.docid) INNER JOIN categories As c ON (t.category_id=c.id)
+			WHERE tasks_fts MATCH ?;`, matchStart, matchEnd, taskColumns)
+	}
+
+	rows, err := db.QueryContext(ctx, sqlStmt, query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search %q: %s", terms, err)
+	}
+	defer rows.Close()
+
+	results := make([]SearchResult, 0)
+	for rows.Next() {
+		var r SearchResult
+		var rank interface{}
+		r.Task, err = scanTask(rows, &r.Highlighted, &r.Snippet, &rank)
+		if err != nil {
+			return nil, err
+		}
+
+		if searchEngine == "fts4" {
+			// snippet of FTS4 is limited to 64 tokens, so the whole description gets highlighted by the offsets
+			r.Highlighted = highlightOffsets(r.Description, r.Highlighted, 0)
+		}
+		switch rank := rank.(type) {
+		case float64:
+			r.Rank = rank
+		case []byte:
+			r.Rank = rankMatchinfo(rank)
+		}
+		if !strings.Contains(r.Snippet, matchStart) {
+			r.Snippet = ""
+		}
+		results = append(results, r)
+	}
+
+	if searchEngine == "fts4" {
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].Rank > results[j].Rank
+		})
+	}
+
+	return results, rows.Err()
+}
+
+// rankMatchinfo computes a tf-idf score from the FTS4 matchinfo blob
+// in the format pcnx, a higher score is a better match
+func rankMatchinfo(blob []byte) float64 {
+	info := make([]uint32, len(blob)/4)
+	for i := range info {
+		info[i] = binary.LittleEndian.Uint32(blob[i*4:])
+	}
+	if len(info) < 3 {
+		return 0
+	}
+
+	phrases, columns, total := int(info[0]), int(info[1]), float64(info[2])
+	score := 0.0
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns; c++ {
+			i := 3 + 3*(p*columns+c)
+			if i+2 >= len(info) || info[i+2] == 0 {
+				continue
+			}
+			hits, docs := float64(info[i]), float64(info[i+2])
+			score += hits * math.Log(1+total/docs)
+		}
+	}
+	return score
+}
+
+// highlightOffsets surrounds the matches in the column of the result of the FTS4 function offsets
+// with the match markers, offsets consists of the column, term, byte offset and size of every match
+func highlightOffsets(s string, offsets string, column int) string {
+	fields := strings.Fields(offsets)
+	var matches [][2]int
+	for i := 0; i+3 < len(fields); i += 4 {
+		var n [4]int
+		for j := range n {
+			n[j], _ = strconv.Atoi(fields[i+j])
+		}
+		if n[0] == column {
+			matches = append(matches, [2]int{n[2], n[2] + n[3]})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i][0] < matches[j][0]
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[0] < last || m[1] > len(s) {
+			continue
+		}
+		b.WriteString(s[last:m[0]] + matchStart + s[m[0]:m[1]] + matchEnd)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// highlightMatches replaces the match markers with colours
+func highlightMatches(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, matchStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], matchEnd)
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		b.WriteString(paint(theme.Match, s[start+len(matchStart):start+end]))
+		s = s[start+end+len(matchEnd):]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// searchCommand renders all tasks matching the search terms given by args
+func searchCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatalln("You need to provide the terms to search for, e.g. gtask search \"clean room\" inv*")
+	}
+
+	results, err := SearchTasks(strings.Join(args, " "))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if machineOutput() {
+		renderJSON(results)
+		return
+	}
+	withPager(!noPager, func() {
+		RenderSearchResults(results)
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_searchQuery(t *testing.T) {
+	tests := []struct {
+		terms   string
+		want    string
+		wantErr bool
+	}{
+		{"clean room", `"clean" "room"`, false},
+		{`"clean room"`, `"clean room"`, false},
+		{"inv*", `inv*`, false},
+		{"foo-bar", `"foo bar"`, false},
+		{"clean OR buy", `"clean" OR "buy"`, false},
+		{`"clean room`, "", true},
+		{"*", "", true},
+		{"-", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.terms, func(t *testing.T) {
+			got, err := searchQuery(tt.terms)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("searchQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("searchQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	task := newTask("Home", "Pay invoice", -1, -1)
+	task.Notes = "The invoice for the new room is in the drawer"
+	insertTask(&task)
+
+	tests := []struct {
+		terms string
+		want  []int64
+	}{
+		{"room", []int64{1, 4}},
+		{`"clean room"`, []int64{1}},
+		{"inv*", []int64{4}},
+		{"drawer", []int64{4}},
+		{"clean OR present", []int64{1, 3}},
+		{"nothing", []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.terms, func(t *testing.T) {
+			results, err := SearchTasks(tt.terms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("Got %d results, expected %d", len(results), len(tt.want))
+			}
+			for _, id := range tt.want {
+				found := false
+				for _, r := range results {
+					found = found || r.Id == id
+				}
+				if !found {
+					t.Errorf("Expected task %d in the results", id)
+				}
+			}
+		})
+	}
+
+	SaveTask("Home", "Sort the old invoices and the invoice archive of the last years", -1, -1)
+	results, _ := SearchTasks("invoice")
+	if len(results) != 2 || results[0].Id != 4 {
+		t.Fatalf("Expected task 4 to be ranked first, got %v", results)
+	}
+	if !strings.Contains(results[0].Snippet, matchStart+"invoice"+matchEnd) {
+		t.Errorf("Expected the match to be highlighted in %q", results[0].Snippet)
+	}
+
+	long := strings.Repeat("word ", 80) + "needle at the end"
+	SaveTask("Home", long, -1, -1)
+	if results, _ := SearchTasks("needle"); len(results) != 1 || results[0].Highlighted != strings.Repeat("word ", 80)+matchStart+"needle"+matchEnd+" at the end" {
+		t.Errorf("Expected the whole long description to be highlighted, got %v", results)
+	}
+
+	// the index has to follow updates and deletes
+	SetNotes([]string{"4"}, "")
+	if results, _ := SearchTasks("drawer"); len(results) != 0 {
+		t.Errorf("Got %d results after removing the notes, expected 0", len(results))
+	}
+	DeleteTasksById([]string{"1"})
+	if results, _ := SearchTasks("clean"); len(results) != 0 {
+		t.Errorf("Got %d results after deleting the task, expected 0", len(results))
+	}
+}
+
+func Test_highlightOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets string
+		want    string
+	}{
+		{"none", "", "Pay the invoice"},
+		{"one", "0 0 8 7", "Pay the \x02invoice\x03"},
+		{"unordered", "0 1 8 7 1 0 0 3 0 0 0 3", "\x02Pay\x03 the \x02invoice\x03"},
+		{"out of range", "0 0 12 10", "Pay the invoice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightOffsets("Pay the invoice", tt.offsets, 0); got != tt.want {
+				t.Errorf("highlightOffsets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSearchIndex_fts5Database(t *testing.T) {
+	if fts5Available() {
+		t.Skip("built with FTS5")
+	}
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	database, err := sql.Open("sqlite3", file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	defer func() {
+		setDB(testDB)
+		createSearchIndex()
+	}()
+	setDB(database)
+	CreateTableTaskCategory()
+	SaveTask("Home", "Clean Room", -1, -1)
+
+	// turn the index into the one of a build with FTS5
+	for _, stmt := range []string{"DROP TRIGGER tasks_fts_insert;", "DROP TRIGGER tasks_fts_delete;",
+		"DROP TRIGGER tasks_fts_update_before;", "DROP TRIGGER tasks_fts_update_after;", "DROP TABLE tasks_fts;"} {
+		if _, err := database.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fts5, err := sql.Open("sqlite3", file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fts5.SetMaxOpenConns(1)
+	searchEngine = "fts5"
+	statements := append([]string{
+		"PRAGMA writable_schema=ON;",
+		`INSERT INTO sqlite_master(type, name, tbl_name, rootpage, sql) VALUES ('table', 'tasks_fts', 'tasks_fts', 0,
+			'CREATE VIRTUAL TABLE tasks_fts USING fts5(description, notes, content=''tasks'', content_rowid=''id'', tokenize=''unicode61'')');`,
+		"PRAGMA writable_schema=OFF;",
+		"PRAGMA schema_version=1000;",
+	}, searchTriggers()...)
+	for _, stmt := range statements {
+		if _, err := fts5.Exec(stmt); err != nil {
+			t.Fatal(stmt, err)
+		}
+	}
+	fts5.Close()
+	if _, err := database.Exec("UPDATE tasks SET notes='in the morning';"); err == nil || !strings.Contains(err.Error(), "fts5") {
+		t.Fatalf("Got %v, expected writing the tasks to fail without the fts5 module", err)
+	}
+
+	CreateTableTaskCategory()
+	if searchEngine != "fts4" {
+		t.Errorf("Got the search engine %q, want fts4", searchEngine)
+	}
+	if task, err := SaveTask("Home", "Buy a new room plant", -1, -1); err != nil || task.Id == 0 {
+		t.Error("Expected the task to be saved")
+	}
+	results, err := SearchTasks("room")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Got %+v, expected the old and the new task to be found", results)
+	}
+}
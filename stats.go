@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// statsPeriods are the periods the activity of the stats can be grouped by
+// with the amount of periods which are shown
+var statsPeriods = map[string]int{"day": 14, "week": 8, "month": 12}
+
+// PeriodStats is the amount of tasks created and completed in the period starting on Start
+type PeriodStats struct {
+	Start     string `json:"start"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// CategoryStats is the completion of the tasks of a category
+type CategoryStats struct {
+	Category       string  `json:"category"`
+	Total          int     `json:"total"`
+	Done           int     `json:"done"`
+	CompletionRate float64 `json:"completion_rate"`
+	// MedianLeadTime is the median of the seconds from creating to completing a task,
+	// nil if no task of the category has a recorded completion time
+	MedianLeadTime *int64 `json:"median_lead_time"`
+}
+
+// Stats is the report of the stats command
+type Stats struct {
+	Period     string          `json:"period"`
+	Activity   []PeriodStats   `json:"activity"`
+	Categories []CategoryStats `json:"categories"`
+	Overdue    int             `json:"overdue"`
+	// streaks are the amount of days in a row on which tasks got completed,
+	// the current streak lasts until the end of today
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+}
+
+// periodStart returns the start of the day, week or month t is in, weeks start on Monday
+func periodStart(t time.Time, period string) time.Time {
+	day := startOfDay(t)
+	switch period {
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// addPeriods adds n days, weeks or months to t
+func addPeriods(t time.Time, period string, n int) time.Time {
+	switch period {
+	case "week":
+		return t.AddDate(0, 0, 7*n)
+	case "month":
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// computeStats builds the report of the tasks at now with the activity grouped by period.
+// Tasks finished before completion times got recorded only count towards the completion rate
+func computeStats(tasks []Task, now time.Time, period string) Stats {
+	stats := Stats{Period: period, Activity: []PeriodStats{}, Categories: []CategoryStats{}}
+
+	current := periodStart(now, period)
+	periods := make(map[string]int)
+	for i := statsPeriods[period] - 1; i >= 0; i-- {
+		start := addPeriods(current, period, -i).Format("2006-01-02")
+		periods[start] = len(stats.Activity)
+		stats.Activity = append(stats.Activity, PeriodStats{Start: start})
+	}
+
+	categories := make(map[string]*CategoryStats)
+	leadTimes := make(map[string][]int64)
+	completedOn := make(map[string]bool)
+	for _, t := range tasks {
+		if i, ok := periods[periodStart(convertDate(t.Created), period).Format("2006-01-02")]; ok {
+			stats.Activity[i].Created++
+		}
+
+		c, ok := categories[t.CategoryName]
+		if !ok {
+			c = &CategoryStats{Category: t.CategoryName}
+			categories[t.CategoryName] = c
+		}
+		c.Total++
+
+		switch {
+		case t.Done:
+			c.Done++
+		case t.Until != 0 && t.Until < now.Unix():
+			stats.Overdue++
+		}
+
+		if !t.Done || t.Completed == 0 {
+			continue
+		}
+		completed := convertDate(t.Completed)
+		if i, ok := periods[periodStart(completed, period).Format("2006-01-02")]; ok {
+			stats.Activity[i].Completed++
+		}
+		completedOn[startOfDay(completed).Format("2006-01-02")] = true
+		if t.Created != 0 && t.Completed >= t.Created {
+			leadTimes[t.CategoryName] = append(leadTimes[t.CategoryName], t.Completed-t.Created)
+		}
+	}
+
+	for name, c := range categories {
+		c.CompletionRate = float64(c.Done) / float64(c.Total)
+		if times := leadTimes[name]; len(times) > 0 {
+			m := median(times)
+			c.MedianLeadTime = &m
+		}
+		stats.Categories = append(stats.Categories, *c)
+	}
+	sort.Slice(stats.Categories, func(i, j int) bool {
+		return stats.Categories[i].Category < stats.Categories[j].Category
+	})
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(completedOn, now)
+	return stats
+}
+
+// median returns the median of the values, it sorts them
+func median(values []int64) int64 {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	n := len(values)
+	if n%2 == 0 {
+		return (values[n/2-1] + values[n/2]) / 2
+	}
+	return values[n/2]
+}
+
+// streaks returns the current and the longest amount of days in a row given as 2006-01-02.
+// The current streak does not break before the end of today
+func streaks(days map[string]bool, now time.Time) (int, int) {
+	next := func(day string, n int) string {
+		t, _ := time.ParseInLocation("2006-01-02", day, now.Location())
+		return t.AddDate(0, 0, n).Format("2006-01-02")
+	}
+
+	longest := 0
+	for day := range days {
+		// only count from the first day of a streak
+		if days[next(day, -1)] {
+			continue
+		}
+		n := 0
+		for d := day; days[d]; d = next(d, 1) {
+			n++
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+
+	current := 0
+	day := startOfDay(now).Format("2006-01-02")
+	if !days[day] {
+		day = next(day, -1)
+	}
+	for ; days[day]; day = next(day, -1) {
+		current++
+	}
+	return current, longest
+}
+
+// RenderStats renders the activity and the categories of the stats as tables followed by the overdue tasks and the streaks
+func RenderStats(stats Stats) {
+	fmt.Fprintln(out)
+	table := tablewriter.NewWriter(out)
+	table.SetHeader([]string{stats.Period, "Created", "Completed"})
+	for _, p := range stats.Activity {
+		table.Append([]string{p.Start, strconv.Itoa(p.Created), strconv.Itoa(p.Completed)})
+	}
+	table.Render()
+
+	fmt.Fprintln(out)
+	table = tablewriter.NewWriter(out)
+	table.SetHeader([]string{"Category", "Done", "Total", "Completion", "Median lead time"})
+	for _, c := range stats.Categories {
+		lead := "-"
+		if c.MedianLeadTime != nil {
+			lead = formatDays(float64(*c.MedianLeadTime) / 86400)
+		}
+		table.Append([]string{c.Category, strconv.Itoa(c.Done), strconv.Itoa(c.Total), fmt.Sprintf("%.0f%%", c.CompletionRate*100), lead})
+	}
+	table.Render()
+
+	fmt.Fprintf(out, "\nOverdue: %s\n", paint(theme.Open, strconv.Itoa(stats.Overdue)))
+	fmt.Fprintf(out, "Current streak: %d days\n", stats.CurrentStreak)
+	fmt.Fprintf(out, "Longest streak: %d days\n\n", stats.LongestStreak)
+}
+
+// statsCommand reports the stats of the tasks matching the filter given by args
+func statsCommand(args []string) {
+	if _, ok := statsPeriods[statsPeriod]; !ok {
+		log.Fatalf("Unknown period %q, use day, week or month\n", statsPeriod)
+	}
+
+	stats := computeStats(FilterTasks(parseFilterArgs(args), "id", "ASC"), time.Now(), statsPeriod)
+	if machineOutput() {
+		renderJSON(stats)
+		return
+	}
+	RenderStats(stats)
+}
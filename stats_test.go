@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_periodStart(t *testing.T) {
+	at := time.Date(2026, 10, 21, 15, 4, 0, 0, time.Local)
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{"day", time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local)},
+		{"week", time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)},
+		{"month", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		if got := periodStart(at, tt.period); !got.Equal(tt.want) {
+			t.Errorf("periodStart(%s) = %v, want %v", tt.period, got, tt.want)
+		}
+	}
+}
+
+func Test_computeStats(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	at := func(day int, hour int) int64 {
+		return time.Date(2026, 10, 21+day, hour, 0, 0, 0, time.Local).Unix()
+	}
+	tasks := []Task{
+		{Id: 1, CategoryName: "home", Created: at(-10, 9), Done: true, Completed: at(-8, 9)},
+		{Id: 2, CategoryName: "home", Created: at(-3, 9), Done: true, Completed: at(-1, 9)},
+		{Id: 3, CategoryName: "home", Created: at(-2, 9), Done: true, Completed: at(0, 9)},
+		{Id: 4, CategoryName: "home", Created: at(0, 8), Until: at(0, 9)},
+		{Id: 5, CategoryName: "work", Created: at(-40, 9), Done: true},
+		{Id: 6, CategoryName: "work", Created: at(-1, 9), Until: at(2, 9)},
+		{Id: 7, CategoryName: "work", Created: at(-9, 9), Done: true, Completed: at(-7, 9)},
+	}
+
+	stats := computeStats(tasks, now, "week")
+	if len(stats.Activity) != 8 {
+		t.Fatalf("Got %d weeks, want 8", len(stats.Activity))
+	}
+	want := map[string]PeriodStats{
+		"2026-10-05": {Start: "2026-10-05", Created: 1, Completed: 0},
+		"2026-10-12": {Start: "2026-10-12", Created: 2, Completed: 2},
+		"2026-10-19": {Start: "2026-10-19", Created: 3, Completed: 2},
+	}
+	for _, p := range stats.Activity[5:] {
+		if !reflect.DeepEqual(p, want[p.Start]) {
+			t.Errorf("Got %+v, want %+v", p, want[p.Start])
+		}
+	}
+
+	day := int64(2 * 86400)
+	wantCategories := []CategoryStats{
+		{Category: "home", Total: 4, Done: 3, CompletionRate: 0.75, MedianLeadTime: &day},
+		{Category: "work", Total: 3, Done: 2, CompletionRate: 2.0 / 3, MedianLeadTime: &day},
+	}
+	if !reflect.DeepEqual(stats.Categories, wantCategories) {
+		t.Errorf("Got %+v, want %+v", stats.Categories, wantCategories)
+	}
+	if stats.Overdue != 1 {
+		t.Errorf("Got %d overdue tasks, want 1", stats.Overdue)
+	}
+	if stats.CurrentStreak != 2 || stats.LongestStreak != 2 {
+		t.Errorf("Got the streaks %d and %d, want 2 and 2", stats.CurrentStreak, stats.LongestStreak)
+	}
+}
+
+func Test_streaks(t *testing.T) {
+	now := time.Date(2026, 10, 21, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		days    []string
+		current int
+		longest int
+	}{
+		{"none", nil, 0, 0},
+		{"today", []string{"2026-10-19", "2026-10-20", "2026-10-21"}, 3, 3},
+		{"until yesterday", []string{"2026-10-19", "2026-10-20"}, 2, 2},
+		{"broken", []string{"2026-10-10", "2026-10-11", "2026-10-12", "2026-10-19"}, 0, 3},
+		{"across months", []string{"2026-09-30", "2026-10-01", "2026-10-21"}, 1, 2},
+	}
+	for _, tt := range tests {
+		days := make(map[string]bool)
+		for _, d := range tt.days {
+			days[d] = true
+		}
+		current, longest := streaks(days, now)
+		if current != tt.current || longest != tt.longest {
+			t.Errorf("%s: got %d and %d, want %d and %d", tt.name, current, longest, tt.current, tt.longest)
+		}
+	}
+}
+
+func TestRenderStats(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	setColors(false)
+	defer func() {
+		out = os.Stdout
+		setColors(true)
+	}()
+
+	lead := int64(36 * 3600)
+	RenderStats(Stats{
+		Period:        "week",
+		Activity:      []PeriodStats{{Start: "2026-10-19", Created: 4, Completed: 2}},
+		Categories:    []CategoryStats{{Category: "home", Total: 4, Done: 3, CompletionRate: 0.75, MedianLeadTime: &lead}},
+		Overdue:       1,
+		CurrentStreak: 2,
+		LongestStreak: 5,
+	})
+
+	got := buf.String()
+	for _, want := range []string{"2026-10-19 |       4 |         2", "home     |    3 |     4 | 75%        | 1d", "Overdue: 1\n", "Current streak: 2 days\n", "Longest streak: 5 days\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// taskbookDefaultBoard is the board of taskbook items without a board, it becomes the default category
+const taskbookDefaultBoard = "My Board"
+
+// tags of imported taskbook items for the flags gtask has no field for
+const (
+	taskbookNoteTag       = "note"
+	taskbookStarredTag    = "starred"
+	taskbookInProgressTag = "in-progress"
+	taskbookArchivedTag   = "archived"
+)
+
+// taskbookItem is a task or note of taskbook's storage or archive file
+type taskbookItem struct {
+	Id          int64    `json:"_id"`
+	Timestamp   int64    `json:"_timestamp"`
+	IsTask      bool     `json:"_isTask"`
+	Description string   `json:"description"`
+	IsStarred   bool     `json:"isStarred"`
+	IsComplete  bool     `json:"isComplete"`
+	InProgress  bool     `json:"inProgress"`
+	Priority    int      `json:"priority"`
+	Boards      []string `json:"boards"`
+}
+
+// task converts the item into a task. The first board is the category and the others tags,
+// notes, starred items and tasks in progress get tagged. Archived items are done
+func (item *taskbookItem) task(archived bool) Task {
+	t := Task{
+		Description: strings.TrimSpace(item.Description),
+		Created:     item.Timestamp / 1000,
+		Done:        item.IsComplete || archived,
+	}
+	switch item.Priority {
+	case 2:
+		t.Priority = PriorityMedium
+	case 3:
+		t.Priority = PriorityHigh
+	}
+
+	for i, board := range item.Boards {
+		// boards are stored with the @ they are given with
+		board = strings.TrimPrefix(board, "@")
+		if i == 0 {
+			if board != taskbookDefaultBoard {
+				t.CategoryName = board
+			}
+			continue
+		}
+		t.Tags = append(t.Tags, normalizeTag(board))
+	}
+	flags := []struct {
+		set bool
+		tag string
+	}{
+		{!item.IsTask, taskbookNoteTag},
+		{item.IsStarred, taskbookStarredTag},
+		{item.InProgress, taskbookInProgressTag},
+		{archived, taskbookArchivedTag},
+	}
+	for _, f := range flags {
+		if f.set {
+			t.Tags = append(t.Tags, f.tag)
+		}
+	}
+	return t
+}
+
+// taskbookFiles returns the storage and archive file of the taskbook directory at path.
+// Without path it is the directory of taskbook's config or ~/.taskbook
+func taskbookFiles(path string) (storage string, archive string, err error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		path = filepath.Join(home, ".taskbook")
+		var c struct {
+			TaskbookDirectory string `json:"taskbookDirectory"`
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(home, ".taskbook.json")); err == nil && json.Unmarshal(data, &c) == nil && c.TaskbookDirectory != "" {
+			path = filepath.Join(strings.Replace(c.TaskbookDirectory, "~", home, 1), ".taskbook")
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+	if !info.IsDir() {
+		if filepath.Base(path) == "archive.json" {
+			return "", path, nil
+		}
+		return path, "", nil
+	}
+	return filepath.Join(path, "storage", "storage.json"), filepath.Join(path, "archive", "archive.json"), nil
+}
+
+// readTaskbookFile reads the items of a storage or archive file ordered by their id
+func readTaskbookFile(path string) ([]taskbookItem, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items map[string]taskbookItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("invalid taskbook file %s: %s", path, err)
+	}
+
+	result := make([]taskbookItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+// ReadTaskbook reads the items of taskbook's storage and archive at path, see taskbookFiles.
+// A missing archive is no error, items without description are skipped
+func ReadTaskbook(path string) ([]Task, []string, error) {
+	storage, archive, err := taskbookFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tasks []Task
+	var skipped []string
+	for _, file := range []string{storage, archive} {
+		if file == "" {
+			continue
+		}
+		items, err := readTaskbookFile(file)
+		if os.IsNotExist(err) && file == archive && storage != "" {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, item := range items {
+			t := item.task(file == archive)
+			if t.Description == "" {
+				skipped = append(skipped, fmt.Sprintf("item %d of %s has no description", item.Id, filepath.Base(file)))
+				continue
+			}
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, skipped, nil
+}
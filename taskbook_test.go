@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadTaskbook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	storage := `{
+		"1": {"_id": 1, "_date": "Mon Oct 19 2026", "_timestamp": 1792400000000, "_isTask": true, "description": "Fix the build", "isStarred": true, "isComplete": false, "inProgress": true, "priority": 3, "boards": ["@coding", "@urgent"]},
+		"2": {"_id": 2, "_date": "Mon Oct 19 2026", "_timestamp": 1792400001000, "_isTask": false, "description": "Ideas for the trip", "isStarred": false, "boards": ["My Board"]},
+		"3": {"_id": 3, "_timestamp": 1792400002000, "_isTask": true, "description": "  ", "boards": ["My Board"]}
+	}`
+	archive := `{"1": {"_id": 1, "_timestamp": 1792300000000, "_isTask": true, "description": "Old task", "isComplete": false, "priority": 2, "boards": ["@home"]}}`
+	for name, data := range map[string]string{"storage/storage.json": storage, "archive/archive.json": archive} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tasks, skipped, err := ReadTaskbook(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Description: "Fix the build", Created: 1792400000, Priority: PriorityHigh, CategoryName: "coding", Tags: []string{"urgent", "starred", "in-progress"}},
+		{Description: "Ideas for the trip", Created: 1792400001, Tags: []string{"note"}},
+		{Description: "Old task", Created: 1792300000, Priority: PriorityMedium, Done: true, CategoryName: "home", Tags: []string{"archived"}},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("Got %+v, want %+v", tasks, want)
+	}
+	if want := []string{"item 3 of storage.json has no description"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Got the skipped items %v, want %v", skipped, want)
+	}
+
+	if err := os.RemoveAll(filepath.Join(dir, "archive")); err != nil {
+		t.Fatal(err)
+	}
+	if tasks, _, err := ReadTaskbook(dir); err != nil || len(tasks) != 2 {
+		t.Errorf("Got %v and %v, expected the tasks of the storage without an archive", tasks, err)
+	}
+	if _, _, err := ReadTaskbook(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// taskwarriorDate is the layout of the dates of Taskwarrior, always in UTC
+const taskwarriorDate = "20060102T150405Z"
+
+// statuses of Taskwarrior tasks
+const (
+	taskwarriorPending   = "pending"
+	taskwarriorCompleted = "completed"
+	taskwarriorDeleted   = "deleted"
+	taskwarriorRecurring = "recurring"
+)
+
+// taskwarriorTask is a task of the JSON written by task export and read by task import
+type taskwarriorTask struct {
+	Uuid        string                  `json:"uuid"`
+	Description string                  `json:"description"`
+	Status      string                  `json:"status"`
+	Entry       string                  `json:"entry,omitempty"`
+	End         string                  `json:"end,omitempty"`
+	Due         string                  `json:"due,omitempty"`
+	Project     string                  `json:"project,omitempty"`
+	Priority    string                  `json:"priority,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Annotations []taskwarriorAnnotation `json:"annotations,omitempty"`
+	// Depends is a list of uuids, older versions of Taskwarrior write them comma separated in a string
+	Depends json.RawMessage `json:"depends,omitempty"`
+}
+
+// taskwarriorAnnotation is a note added to a Taskwarrior task
+type taskwarriorAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// formatTaskwarriorDate formats the timestamp for Taskwarrior, 0 is no date
+func formatTaskwarriorDate(timestamp int64) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return convertDate(timestamp).UTC().Format(taskwarriorDate)
+}
+
+// parseTaskwarriorDate parses a date of Taskwarrior, no date is 0
+func parseTaskwarriorDate(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(taskwarriorDate, value)
+	return t.Unix(), err
+}
+
+// newTaskwarriorTask converts the task for Taskwarrior, uuids maps the ids of tasks to their uuids.
+// Every line of the notes is an annotation
+func newTaskwarriorTask(t *Task, uuids map[int64]string) taskwarriorTask {
+	tw := taskwarriorTask{
+		Uuid:        uuids[t.Id],
+		Description: t.Description,
+		Status:      taskwarriorPending,
+		Entry:       formatTaskwarriorDate(t.Created),
+		Due:         formatTaskwarriorDate(t.Until),
+		Priority:    priorityLetter(t.Priority),
+		Tags:        t.Tags,
+	}
+	if t.Done {
+		tw.Status = taskwarriorCompleted
+		tw.End = formatTaskwarriorDate(t.Completed)
+		if tw.End == "" {
+			// Taskwarrior requires an end date for completed tasks
+			tw.End = tw.Entry
+		}
+	}
+	if t.CategoryName != "default" {
+		tw.Project = t.CategoryName
+	}
+	if t.Notes != "" {
+		for _, line := range strings.Split(t.Notes, "\n") {
+			tw.Annotations = append(tw.Annotations, taskwarriorAnnotation{Entry: tw.Entry, Description: line})
+		}
+	}
+	var depends []string
+	for _, id := range t.Depends {
+		if uuid, ok := uuids[id]; ok {
+			depends = append(depends, uuid)
+		}
+	}
+	if len(depends) > 0 {
+		tw.Depends, _ = json.Marshal(depends)
+	}
+	return tw
+}
+
+// task converts the Taskwarrior task, the ids of the tasks it depends on are looked up in ids
+func (tw *taskwarriorTask) task(ids map[string]int64) (Task, error) {
+	t := Task{
+		Uuid:         tw.Uuid,
+		Description:  tw.Description,
+		Done:         tw.Status == taskwarriorCompleted,
+		CategoryName: tw.Project,
+	}
+	for _, tag := range tw.Tags {
+		t.Tags = append(t.Tags, normalizeTag(tag))
+	}
+	var err error
+	if t.Priority, err = parsePriority(tw.Priority); err != nil {
+		return t, err
+	}
+	if t.Created, err = parseTaskwarriorDate(tw.Entry); err != nil {
+		return t, err
+	}
+	if t.Until, err = parseTaskwarriorDate(tw.Due); err != nil {
+		return t, err
+	}
+	if t.Done {
+		if t.Completed, err = parseTaskwarriorDate(tw.End); err != nil {
+			return t, err
+		}
+	}
+
+	var notes []string
+	for _, a := range tw.Annotations {
+		notes = append(notes, a.Description)
+	}
+	t.Notes = strings.Join(notes, "\n")
+
+	var depends []string
+	if len(tw.Depends) > 0 && json.Unmarshal(tw.Depends, &depends) != nil {
+		var list string
+		if err := json.Unmarshal(tw.Depends, &list); err != nil {
+			return t, fmt.Errorf("invalid depends of %s: %s", tw.Uuid, err)
+		}
+		depends = strings.Split(list, ",")
+	}
+	for _, uuid := range depends {
+		if id, ok := ids[strings.TrimSpace(uuid)]; ok {
+			t.Depends = append(t.Depends, id)
+		}
+	}
+	return t, nil
+}
+
+// uuidsById returns the uuids of the tasks by their id
+func uuidsById(tasks []Task) map[int64]string {
+	uuids := make(map[int64]string)
+	for _, t := range tasks {
+		uuids[t.Id] = t.Uuid
+	}
+	return uuids
+}
+
+// WriteTaskwarrior writes the tasks as JSON for task import
+func WriteTaskwarrior(w io.Writer, tasks []Task) error {
+	uuids := uuidsById(tasks)
+	result := make([]taskwarriorTask, len(tasks))
+	for i := range tasks {
+		result[i] = newTaskwarriorTask(&tasks[i], uuids)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// ReadTaskwarrior reads the JSON of task export. Deleted tasks and the templates
+// of recurring tasks are skipped, waiting tasks are open
+func ReadTaskwarrior(path string) ([]Task, []string, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	var exported []taskwarriorTask
+	if err := json.NewDecoder(f).Decode(&exported); err != nil {
+		return nil, nil, fmt.Errorf("invalid Taskwarrior export: %s", err)
+	}
+
+	// the tasks get numbered to reference each other by id
+	ids := make(map[string]int64)
+	for i, tw := range exported {
+		ids[tw.Uuid] = int64(i + 1)
+	}
+	var tasks []Task
+	var skipped []string
+	for i, tw := range exported {
+		if tw.Status == taskwarriorDeleted || tw.Status == taskwarriorRecurring {
+			skipped = append(skipped, fmt.Sprintf("%s task %q", tw.Status, tw.Description))
+			continue
+		}
+		t, err := tw.task(ids)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid task %q: %s", tw.Description, err)
+		}
+		t.Id = int64(i + 1)
+		tasks = append(tasks, t)
+	}
+	return tasks, skipped, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadTaskwarrior(t *testing.T) {
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(`[
+{"id":1,"description":"Paint the fence","entry":"20261019T100000Z","modified":"20261019T100000Z","project":"Home","status":"pending","uuid":"a1","tags":["Weekend"],"priority":"H","due":"20261025T120000Z","annotations":[{"entry":"20261019T100000Z","description":"buy paint"},{"entry":"20261019T110000Z","description":"white"}],"depends":["b2"],"urgency":9.1},
+{"id":0,"description":"Buy brushes","entry":"20261018T100000Z","end":"20261019T090000Z","status":"completed","uuid":"b2"},
+{"id":0,"description":"Old idea","entry":"20261001T100000Z","status":"deleted","uuid":"c3"},
+{"id":2,"description":"Water plants","entry":"20261018T100000Z","status":"waiting","uuid":"d4","depends":"a1,b2"}
+]`)
+	file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, skipped, err := ReadTaskwarrior(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Task{
+		{Id: 1, Uuid: "a1", Description: "Paint the fence", CategoryName: "Home", Tags: []string{"weekend"}, Priority: PriorityHigh,
+			Created: 1792404000, Until: 1792929600, Notes: "buy paint\nwhite", Depends: []int64{2}},
+		{Id: 2, Uuid: "b2", Description: "Buy brushes", Done: true, Created: 1792317600, Completed: 1792400400},
+		{Id: 4, Uuid: "d4", Description: "Water plants", Created: 1792317600, Depends: []int64{1, 2}},
+	}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("Got %+v, want %+v", tasks, want)
+	}
+	if want := []string{`deleted task "Old idea"`}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("Got the skipped tasks %v, want %v", skipped, want)
+	}
+}
+
+func TestWriteTaskwarrior(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	SetDepends(idFlags{"3"}, []int64{1})
+	SetNotes(idFlags{"1"}, "first\nsecond")
+	TaskDone(idFlags{"2"})
+
+	tasks := AllTasks("id", "ASC")
+	var buf bytes.Buffer
+	if err := WriteTaskwarrior(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	var exported []taskwarriorTask
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 3 {
+		t.Fatalf("Got %d tasks, want 3", len(exported))
+	}
+	if exported[0].Uuid == "" || exported[0].Uuid != tasks[0].Uuid {
+		t.Errorf("Got the uuid %q, expected the uuid %q of the task", exported[0].Uuid, tasks[0].Uuid)
+	}
+	if exported[0].Project != "home" || len(exported[0].Annotations) != 2 || exported[0].Annotations[1].Description != "second" {
+		t.Errorf("Got %+v, expected the project and an annotation per line of the notes", exported[0])
+	}
+	if exported[1].Status != taskwarriorCompleted || exported[1].End == "" {
+		t.Errorf("Got %+v, expected a completed task with an end date", exported[1])
+	}
+	var depends []string
+	if err := json.Unmarshal(exported[2].Depends, &depends); err != nil || !reflect.DeepEqual(depends, []string{exported[0].Uuid}) {
+		t.Errorf("Got the depends %s, expected the uuid of the first task", exported[2].Depends)
+	}
+
+	var again bytes.Buffer
+	if err := WriteTaskwarrior(&again, AllTasks("id", "ASC")); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != buf.String() {
+		t.Error("Expected exporting again to keep the uuids")
+	}
+}
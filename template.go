@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/mattn/go-runewidth"
+)
+
+// colorFuncs are the colours and styles which can be used in templates
+var colorFuncs = map[string]func(a aurora.Aurora, arg interface{}) aurora.Value{
+	"black":     aurora.Aurora.Black,
+	"red":       aurora.Aurora.Red,
+	"green":     aurora.Aurora.Green,
+	"yellow":    aurora.Aurora.Yellow,
+	"blue":      aurora.Aurora.Blue,
+	"magenta":   aurora.Aurora.Magenta,
+	"cyan":      aurora.Aurora.Cyan,
+	"white":     aurora.Aurora.White,
+	"gray":      aurora.Aurora.BrightBlack,
+	"bold":      aurora.Aurora.Bold,
+	"faint":     aurora.Aurora.Faint,
+	"italic":    aurora.Aurora.Italic,
+	"underline": aurora.Aurora.Underline,
+	"strike":    aurora.Aurora.StrikeThrough,
+	"reverse":   aurora.Aurora.Reverse,
+}
+
+// ansiEscape matches the escape sequences of colours and styles
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// visibleWidth returns the amount of cells s takes up in the terminal,
+// ignoring colours and counting wide characters twice
+func visibleWidth(s string) int {
+	return runewidth.StringWidth(ansiEscape.ReplaceAllString(s, ""))
+}
+
+// padRight fills s with spaces on the right until it is width cells wide
+func padRight(s string, width int) string {
+	if w := visibleWidth(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// padLeft fills s with spaces on the left until it is width cells wide
+func padLeft(s string, width int) string {
+	if w := visibleWidth(s); w < width {
+		s = strings.Repeat(" ", width-w) + s
+	}
+	return s
+}
+
+// templateFuncs are the helper functions available in templates
+var templateFuncs = template.FuncMap{
+	"due": func(t Task) string {
+		return timeUntil(t.Until)
+	},
+	"date": func(layout string, unixTimestamp int64) string {
+		if unixTimestamp == 0 {
+			return "-"
+		}
+		return convertDate(unixTimestamp).Format(layout)
+	},
+	"check": func(t Task) string {
+		return t.getCheckBox()
+	},
+	"priority": priorityLetter,
+	"color": func(name string, arg interface{}) (string, error) {
+		f, ok := colorFuncs[name]
+		if !ok {
+			return "", fmt.Errorf("unknown color %q", name)
+		}
+		return f(colors, arg).String(), nil
+	},
+	"pad": func(width int, arg interface{}) string {
+		return padRight(fmt.Sprint(arg), width)
+	},
+	"lpad": func(width int, arg interface{}) string {
+		return padLeft(fmt.Sprint(arg), width)
+	},
+	"trunc": func(width int, s string) string {
+		return runewidth.Truncate(s, width, theme.Ellipsis)
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": strings.Title,
+}
+
+// loadTemplate parses the template given by the template flag.
+// The value is either the name of a template in the config,
+// a file prefixed with @ or the template itself
+func loadTemplate(value string, c Config) (*template.Template, error) {
+	text := value
+	if named, ok := c.Templates[value]; ok {
+		text = named
+	} else if strings.HasPrefix(value, "@") {
+		data, err := ioutil.ReadFile(value[1:])
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+
+	// every task gets rendered on its own line
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	tmpl, err := template.New("task").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %s", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate renders every task with the template
+func RenderTemplate(tmpl *template.Template, tasks []Task) error {
+	for _, t := range tasks {
+		if err := tmpl.Execute(out, t); err != nil {
+			return fmt.Errorf("invalid template: %s", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/logrusorgru/aurora"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	defer func() { out = os.Stdout }()
+
+	tasks := []Task{
+		{Id: 1, Description: "Clean Room", CategoryName: "home", Tags: []string{"weekend"}},
+		{Id: 12, Description: "Add Tests", Done: true, CategoryName: "coding", Priority: PriorityHigh},
+	}
+	c := Config{Templates: map[string]string{"short": "{{.Id}}"}}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"fields", "{{.Id}} {{.Description}}", "1 Clean Room\n12 Add Tests\n"},
+		{"named", "short", "1\n12\n"},
+		{"padding", "{{lpad 3 .Id}}|{{pad 6 .CategoryName}}|", "  1|home  |\n 12|coding|\n"},
+		{"padding ignores colours", `{{pad 5 (color "red" .Id)}}|`, Red(1).String() + "    |\n" + Red(12).String() + "   |\n"},
+		{"helpers", `{{priority .Priority}}{{join .Tags ","}} {{upper .CategoryName}} {{date "2006" .Until}}`, "weekend HOME -\nH CODING -\n"},
+		{"truncate", "{{trunc 6 .Description}}", "Clean…\nAdd T…\n"},
+		{"check", "{{check .}}", Red("⨉").String() + "\n" + Green("✓").String() + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tmpl, err := loadTemplate(tt.template, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := RenderTemplate(tmpl, tasks); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadTemplate(t *testing.T) {
+	file, err := ioutil.TempFile("", "gtask")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	_, _ = file.WriteString("{{.Id}}\n")
+	file.Close()
+
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{"file", "@" + file.Name(), false},
+		{"missing file", "@" + file.Name() + ".missing", true},
+		{"syntax error", "{{.Id", true},
+		{"unknown function", "{{shout .Id}}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadTemplate(tt.template, defaultConfig()); (err != nil) != tt.wantErr {
+				t.Errorf("loadTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	tmpl, _ := loadTemplate(`{{color "pink" .Id}}`, defaultConfig())
+	if err := RenderTemplate(tmpl, []Task{{}}); err == nil {
+		t.Error("Expected an error for an unknown colour")
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// out is the writer all renderers write to,
+// it gets replaced by a buffer if the output might need a pager
+var out io.Writer = os.Stdout
+
+// isTerminal reports whether the file is a terminal and not a pipe or a regular file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalSize returns the width and height of the terminal.
+// It prefers the COLUMNS and LINES environment variables and asks stty otherwise,
+// the returned values are 0 if the size is unknown
+func terminalSize() (width int, height int) {
+	width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+	height, _ = strconv.Atoi(os.Getenv("LINES"))
+	if width > 0 && height > 0 {
+		return width, height
+	}
+
+	// ToDo: Add Support for Windows
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return width, height
+	}
+	defer tty.Close()
+
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = tty
+	output, err := cmd.Output()
+	if err != nil {
+		return width, height
+	}
+
+	size := strings.Fields(string(output))
+	if len(size) != 2 {
+		return width, height
+	}
+	if height <= 0 {
+		height, _ = strconv.Atoi(size[0])
+	}
+	if width <= 0 {
+		width, _ = strconv.Atoi(size[1])
+	}
+	return width, height
+}
+
+// withPager runs render and shows its output in a pager ($PAGER or less)
+// if stdout is a terminal and the output does not fit on the screen
+func withPager(enabled bool, render func()) {
+	if !enabled || !isTerminal(os.Stdout) {
+		render()
+		return
+	}
+
+	var buf bytes.Buffer
+	previous := out
+	out = &buf
+	render()
+	out = previous
+
+	_, height := terminalSize()
+	if height <= 0 || bytes.Count(buf.Bytes(), []byte("\n")) < height {
+		_, _ = buf.WriteTo(out)
+		return
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+	}
+
+	// the pager reads its own reader, so the whole output is left for the fallback
+	output := buf.Bytes()
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		// show the output anyway if the pager is not installed
+		_, _ = out.Write(output)
+		return
+	}
+	_ = cmd.Wait()
+}
+
+// rawMode switches the terminal into raw mode, keys are read one by one without echo.
+// The returned function restores the previous mode
+func rawMode(tty *os.File) (restore func(), err error) {
+	// ToDo: Add Support for Windows
+	stty := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = tty
+		return cmd.Output()
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, _ = stty(strings.TrimSpace(string(state)))
+	}, nil
+}
+
+// readKeys sends the keys typed on the terminal to the channel until reading fails.
+// A key is either a single character or an escape sequence like the arrow keys
+func readKeys(tty io.Reader, keys chan<- string) {
+	buf := make([]byte, 32)
+	for {
+		n, err := tty.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		keys <- string(buf[:n])
+	}
+}
+
+// interactive runs fn with the terminal in raw mode on the alternate screen.
+// fn gets the terminal to draw on and the keys typed on it
+func interactive(fn func(tty io.Writer, keys <-chan string)) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	restore, err := rawMode(tty)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// use the alternate screen and hide the cursor
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan string)
+	go readKeys(tty, keys)
+	fn(tty, keys)
+	return nil
+}
+
+// drawScreen replaces the content of the terminal with the lines
+func drawScreen(w io.Writer, lines []string) {
+	fmt.Fprint(w, "\x1b[H"+strings.Join(lines, "\x1b[K\r\n")+"\x1b[K\x1b[J")
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// names of the bundled themes
+const (
+	themeDark         = "dark"
+	themeLight        = "light"
+	themeHighContrast = "high-contrast"
+	themeASCII        = "ascii"
+)
+
+// stylePlain is the style of text without colours and styles
+const stylePlain = "plain"
+
+// Theme defines the glyphs and the styles of the output.
+// A style is a space separated list of the colours and styles
+// of the template function color like "bold red" or plain for text without a style,
+// settings which are not set are inherited from the base theme
+type Theme struct {
+	// Base is the theme which provides all settings not set by this theme
+	Base string `json:"base,omitempty"`
+
+	// DoneGlyph and OpenGlyph are the check boxes of finished and open tasks
+	DoneGlyph string `json:"doneGlyph,omitempty"`
+	OpenGlyph string `json:"openGlyph,omitempty"`
+	// Ellipsis marks truncated text
+	Ellipsis string `json:"ellipsis,omitempty"`
+
+	Done string `json:"done,omitempty"`
+	Open string `json:"open,omitempty"`
+	// Completed is the style of the description of finished tasks in the aligned view
+	Completed string `json:"completed,omitempty"`
+
+	// DueWeeks, DueDays and DueHours are the styles of due dates
+	// more than a week, more than a day and less than a day away
+	DueWeeks string `json:"dueWeeks,omitempty"`
+	DueDays  string `json:"dueDays,omitempty"`
+	DueHours string `json:"dueHours,omitempty"`
+
+	Header      string `json:"header,omitempty"`
+	Id          string `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Category    string `json:"category,omitempty"`
+	// Muted is the style of secondary information like the reasons of the urgency
+	Muted string `json:"muted,omitempty"`
+	// Match is the style of the words matched by a search
+	Match string `json:"match,omitempty"`
+	// Selected is the style of the selected task in the interactive views
+	Selected string `json:"selected,omitempty"`
+}
+
+// bundledThemes are the themes which can be used without defining them in the config
+var bundledThemes = map[string]Theme{
+	themeDark: {
+		DoneGlyph:   "✓",
+		OpenGlyph:   "⨉",
+		Ellipsis:    "…",
+		Done:        "green",
+		Open:        "red",
+		Completed:   "strike",
+		DueWeeks:    "green",
+		DueDays:     "magenta",
+		DueHours:    "red",
+		Header:      "green",
+		Id:          "bold",
+		Description: "bold",
+		Category:    "underline",
+		Muted:       "gray",
+		Match:       "bold yellow",
+		Selected:    "reverse",
+	},
+	themeLight: {
+		Base:      themeDark,
+		DueWeeks:  "blue",
+		Header:    "bold blue",
+		Muted:     "faint",
+		Match:     "bold magenta",
+		Completed: "faint strike",
+	},
+	// high-contrast does not rely on telling red and green apart
+	themeHighContrast: {
+		Base:      themeDark,
+		Done:      "bold blue",
+		Open:      "bold yellow",
+		DueWeeks:  "blue",
+		DueDays:   "yellow",
+		DueHours:  "bold underline yellow",
+		Header:    "bold underline",
+		Completed: "faint",
+		Muted:     stylePlain,
+		Match:     "bold underline",
+	},
+	themeASCII: {
+		Base:      themeDark,
+		DoneGlyph: "x",
+		OpenGlyph: "-",
+		Ellipsis:  "...",
+	},
+}
+
+var theme = mustTheme(resolveTheme(themeDark, nil))
+
+// mustTheme panics if the bundled theme can not be resolved
+func mustTheme(t Theme, err error) Theme {
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// resolveTheme returns the theme with the given name with all settings
+// inherited from its base themes. Themes defined in the config take precedence
+// over the bundled ones and are based on the bundled theme with the same name
+// or dark if they do not name a base
+func resolveTheme(name string, themes map[string]Theme) (Theme, error) {
+	var resolved Theme
+	seen := map[string]bool{}
+	for name != "" {
+		key := "config " + name
+		t, ok := themes[name]
+		if !ok || seen[key] {
+			key = name
+			if t, ok = bundledThemes[name]; !ok {
+				return resolved, fmt.Errorf("unknown theme %q, expected one of %s", name, strings.Join(themeNames(themes), ", "))
+			}
+		} else if t.Base == "" {
+			t.Base = themeDark
+			if _, ok := bundledThemes[name]; ok {
+				t.Base = name
+			}
+		}
+		if seen[key] {
+			return resolved, fmt.Errorf("theme %q inherits from itself", name)
+		}
+		seen[key] = true
+
+		resolved.inherit(t)
+		name = t.Base
+	}
+	return resolved, resolved.validate()
+}
+
+// inherit sets the settings of the theme which are not set yet to the ones of t
+func (th *Theme) inherit(t Theme) {
+	base := t.fields()
+	for i, f := range th.fields() {
+		if *f.value == "" {
+			*f.value = *base[i].value
+		}
+	}
+}
+
+// themeField is a setting of a theme with its name in the config
+type themeField struct {
+	name  string
+	value *string
+	style bool
+}
+
+// fields returns pointers to all settings of the theme
+func (th *Theme) fields() []themeField {
+	return []themeField{
+		{"doneGlyph", &th.DoneGlyph, false},
+		{"openGlyph", &th.OpenGlyph, false},
+		{"ellipsis", &th.Ellipsis, false},
+		{"done", &th.Done, true},
+		{"open", &th.Open, true},
+		{"completed", &th.Completed, true},
+		{"dueWeeks", &th.DueWeeks, true},
+		{"dueDays", &th.DueDays, true},
+		{"dueHours", &th.DueHours, true},
+		{"header", &th.Header, true},
+		{"id", &th.Id, true},
+		{"description", &th.Description, true},
+		{"category", &th.Category, true},
+		{"muted", &th.Muted, true},
+		{"match", &th.Match, true},
+		{"selected", &th.Selected, true},
+	}
+}
+
+// validate checks that all styles consist of known colours and styles
+func (th *Theme) validate() error {
+	for _, f := range th.fields() {
+		if !f.style {
+			continue
+		}
+		for _, s := range strings.Fields(*f.value) {
+			if _, ok := colorFuncs[s]; !ok && s != stylePlain {
+				return fmt.Errorf("unknown color %q in %s of the theme", s, f.name)
+			}
+		}
+	}
+	return nil
+}
+
+// themeNames returns the sorted names of the bundled themes and the themes of the config
+func themeNames(themes map[string]Theme) []string {
+	names := make([]string, 0, len(bundledThemes)+len(themes))
+	for name := range bundledThemes {
+		if _, ok := themes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// styleCodes are the SGR parameters of the colours and styles
+var styleCodes = map[string]int{
+	"black":     30,
+	"red":       31,
+	"green":     32,
+	"yellow":    33,
+	"blue":      34,
+	"magenta":   35,
+	"cyan":      36,
+	"white":     37,
+	"gray":      90,
+	"bold":      1,
+	"faint":     2,
+	"italic":    3,
+	"underline": 4,
+	"strike":    9,
+	"reverse":   7,
+}
+
+// tableColors converts the style into the colours of tablewriter
+func tableColors(style string) tablewriter.Colors {
+	var c tablewriter.Colors
+	for _, s := range strings.Fields(style) {
+		if code, ok := styleCodes[s]; ok {
+			c = append(c, code)
+		}
+	}
+	return c
+}
+
+// paint applies the style to arg, the first colour or style of the style is the outermost
+func paint(style string, arg interface{}) string {
+	styles := strings.Fields(style)
+	for i := len(styles) - 1; i >= 0; i-- {
+		if f, ok := colorFuncs[styles[i]]; ok {
+			arg = f(colors, arg)
+		}
+	}
+	return fmt.Sprint(arg)
+}
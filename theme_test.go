@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/logrusorgru/aurora"
+	"github.com/olekukonko/tablewriter"
+)
+
+func Test_resolveTheme(t *testing.T) {
+	themes := map[string]Theme{
+		"mine":  {Base: themeASCII, Done: "bold cyan"},
+		"dark":  {Header: "blue"},
+		"plain": {Header: stylePlain},
+		"loop":  {Base: "loop2"},
+		"loop2": {Base: "loop"},
+		"pink":  {Done: "pink"},
+	}
+	tests := []struct {
+		name    string
+		theme   string
+		check   func(th Theme) bool
+		wantErr bool
+	}{
+		{"bundled", themeHighContrast, func(th Theme) bool {
+			return th.Done == "bold blue" && th.DoneGlyph == "✓" && th.Category == "underline"
+		}, false},
+		{"inherits twice", "mine", func(th Theme) bool {
+			return th.Done == "bold cyan" && th.DoneGlyph == "x" && th.Open == "red"
+		}, false},
+		{"overrides bundled", "dark", func(th Theme) bool {
+			return th.Header == "blue" && th.Open == "red"
+		}, false},
+		{"based on dark", "plain", func(th Theme) bool {
+			return th.Header == stylePlain && th.OpenGlyph == "⨉"
+		}, false},
+		{"unknown", "solarized", nil, true},
+		{"cycle", "loop", nil, true},
+		{"invalid color", "pink", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTheme(tt.theme, themes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTheme() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(got) {
+				t.Errorf("resolveTheme() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_paint(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"", "a b"},
+		{stylePlain, "a b"},
+		{"red", Red("a b").String()},
+		{"bold yellow", Bold(Yellow("a b")).String()},
+	}
+	for _, tt := range tests {
+		if got := paint(tt.style, "a b"); got != tt.want {
+			t.Errorf("paint(%q) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+
+	want := tablewriter.Colors{1, 33}
+	if got := tableColors("bold yellow plain"); !reflect.DeepEqual(got, want) {
+		t.Errorf("tableColors() = %v, want %v", got, want)
+	}
+}
+
+func TestTask_getCheckBoxASCII(t *testing.T) {
+	theme = mustTheme(resolveTheme(themeASCII, nil))
+	setColors(false)
+	defer func() {
+		theme = mustTheme(resolveTheme(themeDark, nil))
+		setColors(true)
+	}()
+
+	if got := (&Task{Done: true}).getCheckBox(); got != "x" {
+		t.Errorf("Got %q, expected x", got)
+	}
+	if got := (&Task{}).getCheckBox(); got != "-" {
+		t.Errorf("Got %q, expected -", got)
+	}
+}
@@ -3,8 +3,6 @@ package main
 import (
 	"fmt"
 	"time"
-
-	. "github.com/logrusorgru/aurora"
 )
 
 // converts a unixDateTime to time.Time
@@ -29,13 +27,13 @@ func timeUntil(unixTimestamp int64) string {
 	formatString := ""
 
 	if daysUntil >= 7 {
-		formatString = Green(fmt.Sprintf("%dw", daysUntil)).String()
+		formatString = paint(theme.DueWeeks, fmt.Sprintf("%dw", daysUntil))
 	} else if daysUntil >= 0 && daysUntil <= 1 {
 		hours := int(since / 3600)
 		intResult = hours
-		formatString = Red(fmt.Sprintf("%dh", intResult)).String()
+		formatString = paint(theme.DueHours, fmt.Sprintf("%dh", intResult))
 	} else {
-		formatString = Magenta(fmt.Sprintf("%dd", intResult)).String()
+		formatString = paint(theme.DueDays, fmt.Sprintf("%dd", intResult))
 	}
 
 	return formatString
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// todoTxtDate is the layout of the dates of todo.txt
+const todoTxtDate = "2006-01-02"
+
+// keys of todo.txt for the fields of a task without a place in the format.
+// pri is the priority of completed tasks as todo.txt removes it on completion,
+// created the creation date of completed tasks without completion date as it can only follow one.
+// desc is the description if its words are todo.txt syntax and category the category if it is no valid project
+const (
+	todoTxtDue      = "due"
+	todoTxtDueTime  = "due_time"
+	todoTxtPri      = "pri"
+	todoTxtCreated  = "created"
+	todoTxtNote     = "note"
+	todoTxtId       = "id"
+	todoTxtDep      = "dep"
+	todoTxtDesc     = "desc"
+	todoTxtCategory = "category"
+)
+
+// todoTxtPriority matches the priority at the start of a todo.txt line
+var todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// todoTxtLetter returns the todo.txt priority of a priority, A is high
+func todoTxtLetter(priority int) string {
+	switch priority {
+	case PriorityHigh:
+		return "A"
+	case PriorityMedium:
+		return "B"
+	case PriorityLow:
+		return "C"
+	}
+	return ""
+}
+
+// todoTxtPriorityOf returns the priority of a todo.txt priority, D and below are low
+func todoTxtPriorityOf(letter string) int {
+	switch letter {
+	case "A":
+		return PriorityHigh
+	case "B":
+		return PriorityMedium
+	case "":
+		return PriorityNone
+	}
+	return PriorityLow
+}
+
+// formatTodoTxt converts the task into a line of todo.txt.
+// The category is the last project, tags are contexts and referenced says whether other tasks depend on the task
+func formatTodoTxt(t *Task, referenced bool) string {
+	var parts []string
+	priority := todoTxtLetter(t.Priority)
+	created := t.Created != 0
+	if t.Done {
+		parts = append(parts, "x")
+		if t.Completed != 0 {
+			parts = append(parts, convertDate(t.Completed).Format(todoTxtDate))
+		} else {
+			// todo.txt only allows the creation date after the completion date
+			created = false
+		}
+	} else if priority != "" {
+		parts = append(parts, "("+priority+")")
+	}
+	if created {
+		parts = append(parts, convertDate(t.Created).Format(todoTxtDate))
+	}
+
+	project := t.CategoryName != "" && t.CategoryName != "default"
+	words := strings.Fields(t.Description)
+	literal := strings.Join(words, " ") == t.Description
+	for i, word := range words {
+		literal = literal && !isTodoTxtToken(word, i == 0, project)
+	}
+	parts = append(parts, words...)
+	if project {
+		name := strings.Join(strings.Fields(t.CategoryName), "-")
+		parts = append(parts, "+"+name)
+		if name != t.CategoryName {
+			parts = append(parts, todoTxtCategory+":"+url.PathEscape(t.CategoryName))
+		}
+	}
+	for _, tag := range t.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	if !literal {
+		parts = append(parts, todoTxtDesc+":"+url.PathEscape(t.Description))
+	}
+	if t.Created != 0 && !created {
+		parts = append(parts, todoTxtCreated+":"+convertDate(t.Created).Format(todoTxtDate))
+	}
+	if t.Until != 0 {
+		until := convertDate(t.Until)
+		parts = append(parts, todoTxtDue+":"+until.Format(todoTxtDate))
+		if until.Hour() != 0 || until.Minute() != 0 {
+			parts = append(parts, todoTxtDueTime+":"+until.Format("15:04"))
+		}
+	}
+	if t.Done && priority != "" {
+		parts = append(parts, todoTxtPri+":"+priority)
+	}
+	if t.Notes != "" {
+		parts = append(parts, todoTxtNote+":"+url.PathEscape(t.Notes))
+	}
+	if referenced {
+		parts = append(parts, todoTxtId+":"+strconv.FormatInt(t.Id, 10))
+	}
+	if len(t.Depends) > 0 {
+		parts = append(parts, todoTxtDep+":"+strings.ReplaceAll(joinIds(t.Depends), " ", ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// todoTxtKeys are the keys read by parseTodoTxt
+var todoTxtKeys = map[string]bool{todoTxtDue: true, todoTxtDueTime: true, todoTxtPri: true, todoTxtCreated: true, todoTxtNote: true,
+	todoTxtId: true, todoTxtDep: true, todoTxtDesc: true, todoTxtCategory: true}
+
+// isTodoTxtToken reports whether a word of a description would be read as context or key,
+// as category if no project follows, or as first word as completion mark, priority or date
+func isTodoTxtToken(word string, first bool, projectFollows bool) bool {
+	i := strings.Index(word, ":")
+	token := len(word) > 1 && (word[0] == '+' && !projectFollows || word[0] == '@') ||
+		i > 0 && i < len(word)-1 && todoTxtKeys[word[:i]]
+	if first {
+		_, err := time.Parse(todoTxtDate, word)
+		token = token || word == "x" || todoTxtPriority.MatchString(word) || err == nil
+	}
+	return token
+}
+
+// parseTodoTxtStart reads the completion mark, the priority and the dates at the start of a line
+// from at most limit words and returns how many words it read
+func parseTodoTxtStart(t *Task, words []string, limit int) int {
+	n := 0
+	parseDate := func() (int64, bool) {
+		if n >= limit {
+			return 0, false
+		}
+		d, err := time.ParseInLocation(todoTxtDate, words[n], time.Local)
+		if err != nil {
+			return 0, false
+		}
+		n++
+		return d.Unix(), true
+	}
+
+	if n < limit && words[n] == "x" {
+		t.Done = true
+		n++
+		if completed, ok := parseDate(); ok {
+			t.Completed = completed
+		}
+	} else if n < limit && todoTxtPriority.MatchString(words[n]) {
+		t.Priority = todoTxtPriorityOf(words[n][1:2])
+		n++
+	}
+	if created, ok := parseDate(); ok {
+		t.Created = created
+	}
+	return n
+}
+
+// parseTodoTxt converts a line of todo.txt into a task. The last project is the category,
+// other projects and words with unknown keys stay in the description. If the line has a desc key,
+// it is the description and the words of the line it consists of are no todo.txt syntax
+func parseTodoTxt(line string) (Task, error) {
+	var t Task
+	words := strings.Fields(line)
+
+	var literal []string
+	for _, w := range words {
+		if strings.HasPrefix(w, todoTxtDesc+":") && len(w) > len(todoTxtDesc)+1 {
+			desc, err := url.PathUnescape(w[len(todoTxtDesc)+1:])
+			if err != nil {
+				return t, fmt.Errorf("invalid %s in %q: %s", todoTxtDesc, line, err)
+			}
+			t.Description, literal = desc, strings.Fields(desc)
+		}
+	}
+	start := parseTodoTxtStart(&Task{}, words, len(words))
+	if literal != nil {
+		// the description follows the start, which may have taken its first words
+		matches := func(i int) bool {
+			return i+len(literal) <= len(words) && strings.Join(words[i:i+len(literal)], " ") == strings.Join(literal, " ")
+		}
+		for start > 0 && !matches(start) {
+			start--
+		}
+		if !matches(start) {
+			return t, fmt.Errorf("the %s %q is not in %q", todoTxtDesc, t.Description, line)
+		}
+	}
+	parseTodoTxtStart(&t, words, start)
+	words = words[start+len(literal):]
+
+	var description []string
+	project, dueTime, category := -1, "", ""
+	for _, w := range words {
+		key, value := "", ""
+		if i := strings.Index(w, ":"); i > 0 && i < len(w)-1 {
+			key, value = w[:i], w[i+1:]
+		}
+
+		var err error
+		switch {
+		case len(w) > 1 && w[0] == '+':
+			project = len(description)
+			description = append(description, w)
+		case len(w) > 1 && w[0] == '@':
+			t.Tags = append(t.Tags, normalizeTag(w[1:]))
+		case key == todoTxtDue:
+			var due time.Time
+			due, err = time.ParseInLocation(todoTxtDate, value, time.Local)
+			t.Until = due.Unix()
+		case key == todoTxtDueTime:
+			dueTime = value
+		case key == todoTxtPri && todoTxtPriority.MatchString("("+value+")"):
+			t.Priority = todoTxtPriorityOf(value)
+		case key == todoTxtCreated:
+			var created time.Time
+			created, err = time.ParseInLocation(todoTxtDate, value, time.Local)
+			t.Created = created.Unix()
+		case key == todoTxtDesc:
+		case key == todoTxtCategory:
+			category, err = url.PathUnescape(value)
+		case key == todoTxtNote:
+			t.Notes, err = url.PathUnescape(value)
+		case key == todoTxtId:
+			t.Id, err = strconv.ParseInt(value, 10, 64)
+		case key == todoTxtDep:
+			t.Depends = splitIds(value)
+		default:
+			description = append(description, w)
+		}
+		if err != nil {
+			return t, fmt.Errorf("invalid %s in %q: %s", key, line, err)
+		}
+	}
+
+	if project >= 0 {
+		t.CategoryName = description[project][1:]
+		description = append(description[:project], description[project+1:]...)
+	}
+	if category != "" {
+		t.CategoryName = category
+	}
+	t.Description = strings.TrimSpace(t.Description + " " + strings.Join(description, " "))
+	if t.Description == "" {
+		return t, fmt.Errorf("no description in %q", line)
+	}
+	if dueTime != "" && t.Until != 0 {
+		due, err := time.ParseInLocation(todoTxtDate+" 15:04", convertDate(t.Until).Format(todoTxtDate)+" "+dueTime, time.Local)
+		if err != nil {
+			return t, fmt.Errorf("invalid %s in %q: %s", todoTxtDueTime, line, err)
+		}
+		t.Until = due.Unix()
+	}
+	return t, nil
+}
+
+// WriteTodoTxt writes the tasks as todo.txt, one task per line
+func WriteTodoTxt(w io.Writer, tasks []Task) error {
+	referenced := make(map[int64]bool)
+	for _, t := range tasks {
+		for _, id := range t.Depends {
+			referenced[id] = true
+		}
+	}
+	for i := range tasks {
+		if _, err := fmt.Fprintln(w, formatTodoTxt(&tasks[i], referenced[tasks[i].Id])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadTodoTxt reads the tasks of a todo.txt file, empty lines are skipped
+func ReadTodoTxt(r io.Reader) ([]Task, error) {
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		t, err := parseTodoTxt(scanner.Text())
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, scanner.Err()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_parseTodoTxt(t *testing.T) {
+	day := func(month time.Month, d int) int64 {
+		return time.Date(2026, month, d, 0, 0, 0, 0, time.Local).Unix()
+	}
+	tests := []struct {
+		line    string
+		want    Task
+		wantErr bool
+	}{
+		{"Call Mom", Task{Description: "Call Mom"}, false},
+		{"(A) 2026-10-01 Call Mom +family @phone due:2026-10-25",
+			Task{Description: "Call Mom", Priority: PriorityHigh, Created: day(10, 1), CategoryName: "family", Tags: []string{"phone"}, Until: day(10, 25)}, false},
+		{"(D) Sort +old photos +home rec:1w",
+			Task{Description: "Sort +old photos rec:1w", Priority: PriorityLow, CategoryName: "home"}, false},
+		{"x 2026-10-20 2026-10-01 Pay rent due:2026-10-21 due_time:15:30 pri:B",
+			Task{Description: "Pay rent", Done: true, Completed: day(10, 20), Created: day(10, 1), Until: day(10, 21) + 15*3600 + 30*60, Priority: PriorityMedium}, false},
+		{"Write report note:see%20the%20wiki%0Aasap id:3 dep:1,2",
+			Task{Description: "Write report", Notes: "see the wiki\nasap", Id: 3, Depends: []int64{1, 2}}, false},
+		{"Walk the dog at 10:30", Task{Description: "Walk the dog at 10:30"}, false},
+		{"x x marks due:2026-10-25 desc:x%20marks", Task{Description: "x marks", Done: true, Until: day(10, 25)}, false},
+		{"x marks desc:x%20marks", Task{Description: "x marks"}, false},
+		{"x 2026-10-20 2026-10-20 was payday +work category:the%20work desc:2026-10-20%20was%20payday",
+			Task{Description: "2026-10-20 was payday", Done: true, Completed: day(10, 20), CategoryName: "the work"}, false},
+		{"x Clean created:2026-10-01", Task{Description: "Clean", Done: true, Created: day(10, 1)}, false},
+		{"Call Bob desc:Call%20Anna", Task{}, true},
+		{"(B) +home @errands", Task{}, true},
+		{"Buy milk due:tomorrow", Task{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTodoTxt(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTodoTxt(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTodoTxt(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func Test_formatTodoTxt(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local).Unix()
+	tests := []struct {
+		task       Task
+		referenced bool
+		want       string
+	}{
+		{Task{Description: "Call Mom", CategoryName: "default"}, false, "Call Mom"},
+		{Task{Id: 2, Description: "Call Mom", Priority: PriorityHigh, Created: created, CategoryName: "family", Tags: []string{"phone"}}, true,
+			"(A) 2026-10-01 Call Mom +family @phone id:2"},
+		{Task{Description: "Pay rent", Done: true, Created: created, Completed: created + 86400, Priority: PriorityLow, Until: created}, false,
+			"x 2026-10-02 2026-10-01 Pay rent due:2026-10-01 due_time:09:00 pri:C"},
+		{Task{Description: "Clean", Done: true, Created: created, Notes: "all rooms", Depends: []int64{1, 2}}, false,
+			"x Clean created:2026-10-01 note:all%20rooms dep:1,2"},
+		{Task{Description: "Clean", Done: true}, false, "x Clean"},
+		{Task{Description: "Plan @home trip", CategoryName: "summer holidays"}, false,
+			"Plan @home trip +summer-holidays category:summer%20holidays desc:Plan%20@home%20trip"},
+	}
+	for _, tt := range tests {
+		if got := formatTodoTxt(&tt.task, tt.referenced); got != tt.want {
+			t.Errorf("formatTodoTxt() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTodoTxt_literal(t *testing.T) {
+	descriptions := []string{
+		"Fix +1 bug",
+		"Email @anna about it",
+		"Check due:tomorrow and note:x",
+		"id:7 is done",
+		"x marks the spot",
+		"(B) is no priority",
+		"2026-10-01 was a date",
+		`C:\path and \ and \+already`,
+		"pri:A and dep:1,2",
+		"keep http://example.com and key:value",
+	}
+	for _, d := range descriptions {
+		for _, category := range []string{"default", "work", "side project"} {
+			t.Run(d+" in "+category, func(t *testing.T) {
+				task := Task{Description: d, CategoryName: category}
+				line := formatTodoTxt(&task, false)
+				if line != d && !strings.HasPrefix(line, d+" ") {
+					t.Errorf("Expected %q to start with the description", line)
+				}
+				got, err := parseTodoTxt(line)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := Task{Description: d}
+				if category != "default" {
+					want.CategoryName = category
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Got %+v from %q, want %+v", got, line, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTodoTxt_roundTrip(t *testing.T) {
+	defer cleanDatabase()
+	text := `(A) 2026-10-01 Call Mom +family @phone due:2026-10-25 due_time:18:00 id:1
+x 2026-10-20 2026-10-02 Pay rent +home pri:B
+2026-10-03 Sort +old photos rec:1w +home @weekend note:the%20blue%20box dep:1
+`
+	tasks, err := ReadTodoTxt(strings.NewReader(text + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportBackup(Backup{Version: backupVersion, Tasks: tasks}, conflictKeep); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTodoTxt(&buf, AllTasks("id", "ASC")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != text {
+		t.Errorf("Got %q, want %q", buf.String(), text)
+	}
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tuiRefresh is the interval in which the interface reloads the tasks,
+// so changes made with other gtask commands show up
+const tuiRefresh = 2 * time.Second
+
+// keys of the terminal in raw mode
+const (
+	keyUp        = "\x1b[A"
+	keyDown      = "\x1b[B"
+	keyHome      = "\x1b[H"
+	keyEnd       = "\x1b[F"
+	keyEnter     = "\r"
+	keyEscape    = "\x1b"
+	keyBackspace = "\x7f"
+	keyCtrlC     = "\x03"
+	keyCtrlD     = "\x04"
+	keyCtrlH     = "\b"
+	keyCtrlU     = "\x15"
+)
+
+// tuiHelp explains the keys of the interface
+const tuiHelp = "j/k: move, space: done, e: edit, a: add, m: move, d: delete, /: filter, r: refresh, q: quit"
+
+// modes of the interface, they decide what the typed keys do
+const (
+	tuiNormal = iota
+	tuiFilter
+	tuiPrompt
+	tuiConfirm
+)
+
+// tuiRow is a line of the task list, either the heading of a category or a task
+type tuiRow struct {
+	category *AlignedOutputCategory
+	task     *Task
+}
+
+// tui is the state of the interactive interface.
+// It uses the same functions as the flags of the command line to change tasks
+type tui struct {
+	filterText string
+	filter     *Filter
+
+	rows []tuiRow
+	// cursor is the index of the selected row, it is always a task if there is one
+	cursor int
+	// offset is the index of the first row which is shown
+	offset int
+	// listHeight is the amount of rows which were shown the last time
+	listHeight int
+	// added is the id of a new task which gets selected by the next reload
+	added int64
+
+	mode    int
+	label   string
+	input   string
+	submit  func(input string)
+	message string
+	quit    bool
+}
+
+// newTUI returns the interface showing the tasks matching the filter expression
+func newTUI(filterText string) *tui {
+	u := &tui{listHeight: 1}
+	u.setFilter(filterText)
+	return u
+}
+
+// setFilter shows the tasks matching the filter expression,
+// the previous filter stays active if the expression is invalid
+func (u *tui) setFilter(text string) {
+	u.filterText = text
+	filter, err := ParseFilter(text)
+	if err != nil {
+		u.message = err.Error()
+		return
+	}
+	u.message = ""
+	u.filter = filter
+	u.reload()
+}
+
+// reload reads the tasks from the database and keeps the selected task selected
+func (u *tui) reload() {
+	var selected int64
+	if t := u.selected(); t != nil {
+		selected = t.Id
+	}
+	if u.added != 0 {
+		selected, u.added = u.added, 0
+	}
+
+	sorted := "ASC"
+	if desc {
+		sorted = "DESC"
+	}
+	tasks := FilterTasks(u.filter, orderBy, sorted)
+
+	u.rows = u.rows[:0]
+	for _, a := range sortCategories(groupByCategory(tasks), config) {
+		u.rows = append(u.rows, tuiRow{category: a})
+		if config.CollapseDone && a.Done == a.total {
+			continue
+		}
+		for i := range a.Tasks {
+			u.rows = append(u.rows, tuiRow{task: &a.Tasks[i]})
+		}
+	}
+
+	for i, row := range u.rows {
+		if row.task != nil && row.task.Id == selected {
+			u.cursor = i
+			return
+		}
+	}
+	if u.cursor >= len(u.rows) {
+		u.cursor = len(u.rows) - 1
+	}
+	if u.cursor < 0 {
+		u.cursor = 0
+	}
+	u.move(0)
+}
+
+// selected returns the selected task, nil if no task is shown
+func (u *tui) selected() *Task {
+	if u.cursor < 0 || u.cursor >= len(u.rows) {
+		return nil
+	}
+	return u.rows[u.cursor].task
+}
+
+// move selects the task delta tasks below the selected one or above if delta is negative.
+// If the cursor is on a category the next task gets selected
+func (u *tui) move(delta int) {
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	next := func(from int, step int) int {
+		for i := from + step; i >= 0 && i < len(u.rows); i += step {
+			if u.rows[i].task != nil {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if u.selected() == nil {
+		if i := next(u.cursor-1, 1); i >= 0 {
+			u.cursor = i
+		} else if i := next(u.cursor+1, -1); i >= 0 {
+			u.cursor = i
+		}
+	}
+	for ; delta > 0; delta-- {
+		i := next(u.cursor, step)
+		if i < 0 {
+			return
+		}
+		u.cursor = i
+	}
+}
+
+// ids returns the selected task as ids for the functions of the data layer
+func (u *tui) ids() idFlags {
+	return idFlags{strconv.FormatInt(u.selected().Id, 10)}
+}
+
+// prompt asks for input in the status line, submit gets called with it if enter is pressed
+func (u *tui) prompt(mode int, label string, input string, submit func(input string)) {
+	u.mode, u.label, u.input, u.submit = mode, label, input, submit
+	u.message = ""
+}
+
+// handleKey executes the action of the key depending on the mode
+func (u *tui) handleKey(key string) {
+	switch u.mode {
+	case tuiFilter:
+		switch key {
+		case keyEnter:
+			u.mode = tuiNormal
+		case keyEscape:
+			u.mode = tuiNormal
+			u.setFilter("")
+		default:
+			if text, ok := editInput(u.filterText, key); ok {
+				u.setFilter(text)
+			}
+		}
+		return
+
+	case tuiPrompt:
+		switch key {
+		case keyEnter:
+			u.mode = tuiNormal
+			if strings.TrimSpace(u.input) != "" {
+				u.submit(strings.TrimSpace(u.input))
+				u.reload()
+			}
+		case keyEscape:
+			u.mode = tuiNormal
+		default:
+			u.input, _ = editInput(u.input, key)
+		}
+		return
+
+	case tuiConfirm:
+		u.mode = tuiNormal
+		if key == "y" || key == "Y" {
+			u.submit("y")
+			u.reload()
+		}
+		return
+	}
+
+	task := u.selected()
+	switch key {
+	case "q", keyCtrlC:
+		u.quit = true
+	case "j", keyDown:
+		u.move(1)
+	case "k", keyUp:
+		u.move(-1)
+	case keyCtrlD:
+		u.move(u.listHeight / 2)
+	case keyCtrlU:
+		u.move(-u.listHeight / 2)
+	case "g", keyHome:
+		u.cursor = 0
+		u.move(0)
+	case "G", keyEnd:
+		u.cursor = len(u.rows) - 1
+		u.move(0)
+	case "/":
+		u.mode = tuiFilter
+	case "r":
+		u.reload()
+		u.message = "Refreshed"
+	case "?":
+		u.message = tuiHelp
+	case "a":
+		category := ""
+		if task != nil {
+			category = task.CategoryName
+		} else if u.cursor < len(u.rows) {
+			category = u.rows[u.cursor].category.Category
+		}
+		label := "Add"
+		if category != "" {
+			label += " to " + category
+		}
+		u.prompt(tuiPrompt, label, "", func(input string) {
+			t := newTask(category, input, -1, -1)
+			if err := insertTask(&t); err != nil {
+				u.message = err.Error()
+				return
+			}
+			if t.Id == 0 {
+				u.message = fmt.Sprintf("A task %q already exists", input)
+				return
+			}
+			u.added = t.Id
+			u.message = fmt.Sprintf("Added task %d", t.Id)
+		})
+	}
+	if task == nil {
+		return
+	}
+
+	switch key {
+	case " ", "x":
+		if task.Done {
+			TaskUndone(u.ids())
+		} else {
+			TaskDone(u.ids())
+		}
+		u.reload()
+	case "e":
+		// descriptions are unique, so only the task under the cursor gets edited
+		id := task.Id
+		u.prompt(tuiPrompt, "Edit", task.Description, func(input string) {
+			if err := SetDescription(id, input); err != nil {
+				u.message = err.Error()
+			}
+		})
+	case "m":
+		ids := u.ids()
+		u.prompt(tuiPrompt, "Move to category", "", func(input string) {
+			id, err := GetOrCreateCategory(input)
+			if err != nil {
+				u.message = err.Error()
+				return
+			}
+			UpdateCategory(id, ids)
+		})
+	case "d":
+		ids, id := u.ids(), task.Id
+		u.prompt(tuiConfirm, fmt.Sprintf("Delete task %d? [y/N]", id), "", func(string) {
+			DeleteTasksById(ids)
+			u.message = fmt.Sprintf("Deleted task %d", id)
+		})
+	}
+}
+
+// editInput applies the key to the text of an input, ok is false if the key is no input
+func editInput(text string, key string) (string, bool) {
+	if key == keyBackspace || key == keyCtrlH {
+		if text == "" {
+			return text, false
+		}
+		_, size := utf8.DecodeLastRuneInString(text)
+		return text[:len(text)-size], true
+	}
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return text, false
+		}
+	}
+	return text + key, true
+}
+
+// view returns the lines of the screen with the given size
+func (u *tui) view(width int, height int) []string {
+	if width <= 0 {
+		width = 80
+	}
+	if height < 3 {
+		height = 3
+	}
+
+	title := "gtask"
+	if u.filterText != "" {
+		title += " - " + u.filterText
+	}
+	lines := []string{paint(theme.Header, fitText(title, width, true)[0])}
+
+	u.listHeight = height - 2
+	if u.cursor < u.offset {
+		u.offset = u.cursor
+	}
+	if u.cursor >= u.offset+u.listHeight {
+		u.offset = u.cursor - u.listHeight + 1
+	}
+	if u.offset > 0 && u.offset+u.listHeight > len(u.rows) {
+		u.offset = len(u.rows) - u.listHeight
+		if u.offset < 0 {
+			u.offset = 0
+		}
+	}
+
+	for i := u.offset; i < u.offset+u.listHeight; i++ {
+		if i >= len(u.rows) {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, u.rowLine(u.rows[i], i == u.cursor, width))
+	}
+	if len(u.rows) == 0 {
+		lines[1] = "No tasks"
+	}
+
+	status := u.message
+	switch u.mode {
+	case tuiFilter:
+		status = "/" + u.filterText
+	case tuiPrompt:
+		status = u.label + ": " + u.input
+	case tuiConfirm:
+		status = u.label
+	}
+	if status == "" {
+		status = "? help"
+	}
+	return append(lines, fitText(status, width, true)[0])
+}
+
+// rowLine renders a row of the task list fitted into the width
+func (u *tui) rowLine(row tuiRow, selected bool, width int) string {
+	if row.task == nil {
+		a := row.category
+		return fmt.Sprintf("%s - [%d/%d]", paint(theme.Category, strings.Title(a.Category)), a.Done, a.total)
+	}
+
+	t := row.task
+	marker := "  "
+	if selected {
+		marker = "> "
+	}
+	style := ""
+	if t.Done {
+		style = theme.Completed
+	}
+	due := ""
+	if t.Until != 0 {
+		due = timeUntil(t.Until)
+	}
+	prefix := fmt.Sprintf("%s%s %d ", marker, t.getCheckBox(), t.Id)
+	line := layoutLines(prefix, t.Description, style, due, width, true)[0]
+	if selected {
+		// the colours of the line would end the style of the selection
+		line = paint(theme.Selected, padRight(ansiEscape.ReplaceAllString(line, ""), width))
+	}
+	return line
+}
+
+// draw writes the screen to the terminal
+func (u *tui) draw(w io.Writer, width int, height int) {
+	drawScreen(w, u.view(width, height))
+}
+
+// tuiCommand runs the interactive interface showing the tasks matching the filter given by args
+func tuiCommand(args []string) {
+	u := newTUI(strings.Join(args, " "))
+
+	err := interactive(func(tty io.Writer, keys <-chan string) {
+		ticker := time.NewTicker(tuiRefresh)
+		defer ticker.Stop()
+
+		for !u.quit {
+			width, height := terminalSize()
+			u.draw(tty, width, height)
+
+			select {
+			case key, ok := <-keys:
+				if !ok {
+					return
+				}
+				u.handleKey(key)
+			case <-ticker.C:
+				if u.mode == tuiNormal {
+					u.reload()
+				}
+			}
+		}
+	})
+	if err != nil {
+		log.Fatalln("gtask ui needs a terminal:", err)
+	}
+}
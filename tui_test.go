@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// typeKeys sends every key to the interface or the picker
+func typeKeys(u interface{ handleKey(key string) }, keys ...string) {
+	for _, key := range keys {
+		u.handleKey(key)
+	}
+}
+
+func TestTUI_navigation(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	u := newTUI("")
+	tests := []struct {
+		keys []string
+		want int64
+	}{
+		{nil, 2},
+		{[]string{"j"}, 1},
+		{[]string{"j", "j"}, 3},
+		{[]string{keyUp}, 1},
+		{[]string{"g"}, 2},
+		{[]string{"G"}, 3},
+		{[]string{"k", "k", "k"}, 2},
+	}
+	for _, tt := range tests {
+		typeKeys(u, tt.keys...)
+		if got := u.selected(); got == nil || got.Id != tt.want {
+			t.Errorf("After %q got %v selected, want task %d", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestTUI_actions(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	u := newTUI("")
+	typeKeys(u, "j", " ")
+	if _, done := CountTasks(nil); done != 1 || !u.selected().Done {
+		t.Errorf("Expected task 1 to be done and still selected")
+	}
+	typeKeys(u, "x")
+	if _, done := CountTasks(nil); done != 0 {
+		t.Errorf("Expected task 1 to be open again")
+	}
+
+	typeKeys(u, "e", keyBackspace, keyBackspace, keyBackspace, keyBackspace, "Kitchen", keyEnter)
+	if got := u.selected().Description; got != "Clean Kitchen" {
+		t.Errorf("Got description %q, want Clean Kitchen", got)
+	}
+
+	typeKeys(u, "m", "work", keyEnter)
+	if got := u.selected().CategoryName; got != "work" {
+		t.Errorf("Got category %q, want work", got)
+	}
+
+	typeKeys(u, "a", "Water plants", keyEnter)
+	if got := u.selected(); got.Description != "Water plants" || got.CategoryName != "work" {
+		t.Errorf("Expected the new task to be added to work and selected, got %v", got)
+	}
+
+	typeKeys(u, "a", "Add Tests", keyEnter)
+	if got := u.selected(); got.Description != "Water plants" || !strings.Contains(u.message, "already exists") {
+		t.Errorf("Expected the existing task to be refused and the selection to stay, got %v and %q", got, u.message)
+	}
+
+	typeKeys(u, "d", "n")
+	if total, _ := CountTasks(nil); total != 4 {
+		t.Errorf("Got %d tasks, expected the deletion to be cancelled", total)
+	}
+	typeKeys(u, "d", "y")
+	if total, _ := CountTasks(nil); total != 3 {
+		t.Errorf("Got %d tasks, expected the selected task to be deleted", total)
+	}
+
+	typeKeys(u, "q")
+	if !u.quit {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestTUI_filter(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+	setColors(false)
+	defer setColors(true)
+
+	u := newTUI("")
+	typeKeys(u, "/", "c", "a", "t", ":", "h", "o")
+	if len(u.rows) != 0 {
+		t.Errorf("Expected no tasks in the category ho, got %d rows", len(u.rows))
+	}
+	typeKeys(u, "m", "e", keyEnter)
+	if len(u.rows) != 3 || u.selected().Id != 1 {
+		t.Errorf("Expected the category home with tasks 1 and 3, got %d rows", len(u.rows))
+	}
+
+	lines := u.view(40, 6)
+	want := []string{"gtask - cat:home", "Home - [0/2]", "> ⨉ 1 Clean Room", "  ⨉ 3 Buy Present", "", "? help"}
+	if len(lines) != len(want) {
+		t.Fatalf("Got %q, want %q", lines, want)
+	}
+	for i := range want {
+		if strings.TrimRight(lines[i], " ") != want[i] {
+			t.Errorf("Got line %q, want %q", lines[i], want[i])
+		}
+	}
+
+	typeKeys(u, "/", keyEscape)
+	if u.filterText != "" || len(u.rows) != 5 {
+		t.Errorf("Expected escape to clear the filter, got %q with %d rows", u.filterText, len(u.rows))
+	}
+}
+
+func Test_editInput(t *testing.T) {
+	tests := []struct {
+		text, key, want string
+		ok              bool
+	}{
+		{"ab", "c", "abc", true},
+		{"ab", keyBackspace, "a", true},
+		{"", keyBackspace, "", false},
+		{"aü", keyCtrlH, "a", true},
+		{"ab", keyUp, "ab", false},
+		{"ab", "cd", "abcd", true},
+	}
+	for _, tt := range tests {
+		if got, ok := editInput(tt.text, tt.key); got != tt.want || ok != tt.ok {
+			t.Errorf("editInput(%q, %q) = %q, %v, want %q, %v", tt.text, tt.key, got, ok, tt.want, tt.ok)
+		}
+	}
+}
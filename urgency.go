@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// urgencyOrder is the order by key sorting the tasks by their urgency
+const urgencyOrder = "urgency"
+
+// priorities of a task
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// UrgencyCoefficients weight the factors of the urgency of a task
+type UrgencyCoefficients struct {
+	// Due gets scaled by the proximity of the due date,
+	// from 0.2 two weeks ahead to 1.0 a week overdue
+	Due float64 `json:"due"`
+	// Overdue gets added for tasks which are past their due date
+	Overdue float64 `json:"overdue"`
+	// Priority gets scaled by 1.0 for high, 0.65 for medium and 0.3 for low priority
+	Priority float64 `json:"priority"`
+	// Age gets scaled by the age of the task, reaching 1.0 after a year
+	Age float64 `json:"age"`
+	// Blocked gets added for tasks depending on open tasks
+	Blocked float64 `json:"blocked"`
+	// Tagged gets added for tasks with at least one tag
+	Tagged float64 `json:"tagged"`
+	// Tags get added for tasks with the given tag
+	Tags map[string]float64 `json:"tags"`
+}
+
+// defaultUrgencyCoefficients are the coefficients used if the config does not set them
+func defaultUrgencyCoefficients() UrgencyCoefficients {
+	return UrgencyCoefficients{
+		Due:      12,
+		Overdue:  3,
+		Priority: 6,
+		Age:      2,
+		Blocked:  -5,
+		Tagged:   1,
+		Tags:     map[string]float64{},
+	}
+}
+
+// UrgencyReason is a factor which contributed to the urgency of a task
+type UrgencyReason struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+func (r UrgencyReason) String() string {
+	return fmt.Sprintf("%s %+.1f", r.Name, r.Value)
+}
+
+// Urgency computes how urgent the task is and returns the reasons behind the score.
+// Done tasks have an urgency of 0
+func (c UrgencyCoefficients) Urgency(t *Task, now time.Time) (float64, []UrgencyReason) {
+	if t.Done {
+		return 0, nil
+	}
+
+	var reasons []UrgencyReason
+	add := func(name string, value float64) {
+		// skip factors which would be shown as +0.0
+		if math.Abs(value) >= 0.05 {
+			reasons = append(reasons, UrgencyReason{name, value})
+		}
+	}
+
+	if t.Until != 0 {
+		days := time.Unix(t.Until, 0).Sub(now).Hours() / 24
+		var proximity float64
+		switch {
+		case days <= -7:
+			proximity = 1
+		case days >= 14:
+			proximity = 0.2
+		default:
+			proximity = (14-days)*0.8/21 + 0.2
+		}
+		if days < 0 {
+			add("overdue", c.Overdue)
+			add(fmt.Sprintf("due %s ago", formatDays(-days)), c.Due*proximity)
+		} else {
+			add(fmt.Sprintf("due in %s", formatDays(days)), c.Due*proximity)
+		}
+	}
+
+	switch t.Priority {
+	case PriorityHigh:
+		add("priority H", c.Priority)
+	case PriorityMedium:
+		add("priority M", c.Priority*0.65)
+	case PriorityLow:
+		add("priority L", c.Priority*0.3)
+	}
+
+	if t.Created != 0 {
+		age := now.Sub(time.Unix(t.Created, 0)).Hours() / 24 / 365
+		add("age", c.Age*math.Min(math.Max(age, 0), 1))
+	}
+
+	if t.Blocked {
+		add("blocked", c.Blocked)
+	}
+
+	if len(t.Tags) > 0 {
+		add("tagged", c.Tagged)
+	}
+	for _, tag := range t.Tags {
+		add("+"+tag, c.Tags[tag])
+	}
+
+	score := 0.0
+	for _, r := range reasons {
+		score += r.Value
+	}
+	return score, reasons
+}
+
+// formatDays formats an amount of days as 3d or, if less than a day, as 5h
+func formatDays(days float64) string {
+	if days < 1 {
+		return fmt.Sprintf("%dh", int(days*24))
+	}
+	return fmt.Sprintf("%dd", int(days))
+}
+
+// sortByUrgency sorts the tasks by their urgency, the most urgent first.
+// Tasks with the same urgency keep their order
+func sortByUrgency(tasks []Task, c UrgencyCoefficients, now time.Time) {
+	scores := make(map[int64]float64, len(tasks))
+	for i := range tasks {
+		scores[tasks[i].Id], _ = c.Urgency(&tasks[i], now)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return scores[tasks[i].Id] > scores[tasks[j].Id]
+	})
+}
+
+// parsePriority parses H, M, L or their long forms into a priority
+func parsePriority(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return PriorityNone, nil
+	case "l", "low":
+		return PriorityLow, nil
+	case "m", "medium":
+		return PriorityMedium, nil
+	case "h", "high":
+		return PriorityHigh, nil
+	default:
+		return 0, fmt.Errorf("unknown priority %q, expected H, M or L", s)
+	}
+}
+
+// priorityLetter returns the short form H, M or L of the priority
+func priorityLetter(priority int) string {
+	switch priority {
+	case PriorityHigh:
+		return "H"
+	case PriorityMedium:
+		return "M"
+	case PriorityLow:
+		return "L"
+	default:
+		return ""
+	}
+}
+
+// nextCommand renders the most urgent open tasks matching the filter
+// expression given by args together with the reasons behind their urgency
+func nextCommand(args []string) {
+	filter := parseFilterArgs(append([]string{"open"}, args...))
+	tasks := FilterTasks(filter, urgencyOrder, "ASC")
+	if amount > 0 && len(tasks) > amount {
+		tasks = tasks[:amount]
+	}
+	if len(tasks) == 0 && !machineOutput() {
+		// nothing to do is no error, scripts get an empty list
+		fmt.Fprintln(out, "Nothing to do, there are no open tasks matching the filter")
+		return
+	}
+	if machineOutput() {
+		renderJSON(newNextJSON(tasks, config.Urgency, time.Now()))
+		return
+	}
+	RenderNext(tasks, config.Urgency, time.Now())
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUrgencyCoefficients_Urgency(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	day := int64(86400)
+	c := defaultUrgencyCoefficients()
+	c.Tags["urgent"] = 4
+
+	tests := []struct {
+		name        string
+		task        Task
+		want        float64
+		wantReasons int
+	}{
+		{"done", Task{Done: true, Priority: PriorityHigh}, 0, 0},
+		{"nothing", Task{}, 0, 0},
+		{"due in two weeks", Task{Until: now.Unix() + 14*day}, 12 * 0.2, 1},
+		{"a week overdue", Task{Until: now.Unix() - 7*day}, 12 + 3, 2},
+		{"high priority", Task{Priority: PriorityHigh}, 6, 1},
+		{"low priority", Task{Priority: PriorityLow}, 6 * 0.3, 1},
+		{"a year old", Task{Created: now.Unix() - 400*day}, 2, 1},
+		{"blocked", Task{Blocked: true, Priority: PriorityHigh}, 6 - 5, 2},
+		{"tagged", Task{Tags: []string{"urgent", "home"}}, 1 + 4, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reasons := c.Urgency(&tt.task, now)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("Urgency() = %v, want %v", got, tt.want)
+			}
+			if len(reasons) != tt.wantReasons {
+				t.Errorf("Got %d reasons %v, expected %d", len(reasons), reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func Test_parsePriority(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{"H", PriorityHigh, false},
+		{"medium", PriorityMedium, false},
+		{"l", PriorityLow, false},
+		{"", PriorityNone, false},
+		{"A", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parsePriority(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePriority(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parsePriority(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestFilterTasksByUrgency(t *testing.T) {
+	defer cleanDatabase()
+	createThreeTasks()
+
+	SetPriority([]string{"3"}, PriorityHigh)
+	SetPriority([]string{"1"}, PriorityLow)
+	SetDepends([]string{"3"}, []int64{2})
+
+	tasks := FilterTasks(nil, urgencyOrder, "ASC")
+	if tasks[1].Id != 3 || !tasks[1].Blocked {
+		t.Errorf("Expected task 3 to be blocked by task 2")
+	}
+	if tasks[0].Id != 1 {
+		t.Errorf("Expected the low priority task to be the most urgent while task 3 is blocked, got %d", tasks[0].Id)
+	}
+
+	if tasks := FilterTasks(nil, urgencyOrder, "DESC"); tasks[0].Id != 2 {
+		t.Errorf("Expected the least urgent task 2 first when sorting descending, got %d", tasks[0].Id)
+	}
+
+	TaskDone([]string{"2"})
+	tasks = FilterTasksPage(nil, urgencyOrder, "ASC", 1, 0)
+	if len(tasks) != 1 || tasks[0].Id != 3 || tasks[0].Blocked {
+		t.Errorf("Expected the unblocked high priority task 3 first, got %v", tasks)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// View represents a saved combination of filter, sort order, renderer and columns.
+// The renderer is one of the output formats
+type View struct {
+	Name     string   `json:"name"`
+	Filter   string   `json:"filter"`
+	OrderBy  string   `json:"order_by"`
+	Desc     bool     `json:"desc"`
+	Renderer string   `json:"renderer"`
+	Columns  []string `json:"columns"`
+	Template string   `json:"template"`
+}
+
+// ErrViewNotFound gets returned if there is no view with the given name
+var ErrViewNotFound = errors.New("view does not exist, use gtask view to list all views")
+
+func createViewTable() {
+	viewTable := `CREATE TABLE IF NOT EXISTS views (
+					name text not null primary key,
+					filter text not null,
+					order_by text not null,
+					descending boolean not null,
+					renderer text not null,
+					columns text not null
+				);`
+	_, err := db.Exec(viewTable)
+	checkErrorQueries(err, viewTable)
+
+	addColumn("views", "template", "text not null DEFAULT ''")
+}
+
+// SaveView saves the view, replacing an existing view with the same name
+func SaveView(v View) error {
+	if v.Name == "" || strings.ContainsAny(v.Name, " \t") {
+		return fmt.Errorf("invalid view name %q, it must not be empty or contain spaces", v.Name)
+	}
+	if v.Name == "save" || v.Name == "rm" {
+		return fmt.Errorf("invalid view name %q, save and rm are sub commands of view", v.Name)
+	}
+	if _, err := ParseFilter(v.Filter); err != nil {
+		return err
+	}
+	if err := validateColumns(v.Columns); err != nil {
+		return err
+	}
+	if err := validateFormat(v.Renderer); err != nil {
+		return err
+	}
+
+	createViewTable()
+	sqlStmt := `INSERT OR REPLACE INTO views (name, filter, order_by, descending, renderer, columns, template) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.Exec(sqlStmt, v.Name, v.Filter, v.OrderBy, v.Desc, v.Renderer, strings.Join(v.Columns, ","), v.Template)
+	checkErrorQueries(err, sqlStmt)
+	return err
+}
+
+// GetView returns the view with the given name
+func GetView(name string) (View, error) {
+	createViewTable()
+	row := db.QueryRow(`SELECT name, filter, order_by, descending, renderer, columns, template FROM views WHERE name=$1`, name)
+	v, err := scanView(row)
+	if err == sql.ErrNoRows {
+		return v, fmt.Errorf("%s: %s", name, ErrViewNotFound)
+	}
+	return v, err
+}
+
+// AllViews returns all saved views ordered by name
+func AllViews() []View {
+	createViewTable()
+	sqlStmt := `SELECT name, filter, order_by, descending, renderer, columns, template FROM views ORDER BY name`
+	rows, err := db.QueryContext(ctx, sqlStmt)
+	views := make([]View, 0)
+	if err != nil {
+		checkErrorQueries(err, sqlStmt)
+		return views
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			log.Fatal(err)
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// DeleteView deletes the view with the given name
+func DeleteView(name string) error {
+	createViewTable()
+	sqlStmt := `DELETE FROM views WHERE name=$1`
+	res, err := db.Exec(sqlStmt, name)
+	if err != nil {
+		checkErrorQueries(err, sqlStmt)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%s: %s", name, ErrViewNotFound)
+	}
+	return nil
+}
+
+// scanView scans a row of the views table
+func scanView(row interface{ Scan(...interface{}) error }) (View, error) {
+	var v View
+	var columns string
+	err := row.Scan(&v.Name, &v.Filter, &v.OrderBy, &v.Desc, &v.Renderer, &columns, &v.Template)
+	if columns != "" {
+		v.Columns = strings.Split(columns, ",")
+	}
+	return v, err
+}
+
+// currentView returns a view with the given name and filter
+// made from the flags of the current call
+func currentView(name string, filter string) View {
+	v := View{Name: name, Filter: filter, OrderBy: orderBy, Desc: desc, Renderer: outputFormat(), Template: templateText}
+	if columnList != "" {
+		v.Columns = strings.Split(columnList, ",")
+	}
+	return v
+}
+
+// applyView sets the flags to the values of the view,
+// flags which were given on the command line take precedence
+func applyView(v View) {
+	given := givenFlags()
+	if !given["o"] {
+		orderBy = v.OrderBy
+	}
+	if !given["desc"] {
+		desc = v.Desc
+	}
+	if !given["format"] && !given["table"] && !given["json"] && !given["ndjson"] && !given["template"] {
+		outputFormatName = v.Renderer
+	}
+	if !given["columns"] {
+		columnList = strings.Join(v.Columns, ",")
+	}
+	if !given["template"] {
+		templateText = v.Template
+	}
+}
+
+// viewCommand lists, saves, deletes or runs views
+//
+//	gtask view                      lists all views
+//	gtask view save NAME [filter]   saves the filter and the flags -o, -desc, -table and -columns
+//	gtask view rm NAME              deletes the view
+//	gtask view NAME [filter]        lists the tasks of the view, further narrowed by the filter
+func viewCommand(args []string) {
+	if len(args) == 0 {
+		if machineOutput() {
+			renderJSON(AllViews())
+		} else {
+			RenderTableViews(AllViews())
+		}
+		return
+	}
+
+	switch args[0] {
+	case "save":
+		if len(args) < 2 {
+			log.Fatalln("You need to provide the name of the view, e.g. gtask view save work-today cat:work due:<1d")
+		}
+		if err := SaveView(currentView(args[1], strings.Join(args[2:], " "))); err != nil {
+			log.Fatalln(err)
+		}
+
+	case "rm":
+		if len(args) < 2 {
+			log.Fatalln("You need to provide the name of the view to delete")
+		}
+		for _, name := range args[1:] {
+			if err := DeleteView(name); err != nil {
+				log.Fatalln(err)
+			}
+		}
+
+	default:
+		v, err := GetView(args[0])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		applyView(v)
+		listCommand(append(strings.Fields(v.Filter), args[1:]...))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSaveView(t *testing.T) {
+	defer cleanDatabase()
+
+	tests := []struct {
+		name    string
+		view    View
+		wantErr bool
+	}{
+		{"valid", View{"work-today", "cat:work due:<1d", urgencyOrder, false, formatTable, []string{"id", "description"}, ""}, false},
+		{"replace", View{"work-today", "cat:work", "id", true, formatTemplate, nil, "{{.Id}} {{.Description}}"}, false},
+		{"empty name", View{Name: ""}, true},
+		{"name with spaces", View{Name: "work today"}, true},
+		{"reserved name", View{Name: "rm"}, true},
+		{"invalid filter", View{Name: "broken", Filter: "foo:bar"}, true},
+		{"invalid column", View{Name: "broken", Columns: []string{"color"}}, true},
+		{"invalid renderer", View{Name: "broken", Renderer: "html"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SaveView(tt.view); (err != nil) != tt.wantErr {
+				t.Errorf("SaveView() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	views := AllViews()
+	if len(views) != 1 {
+		t.Fatalf("Got %d views, expected 1", len(views))
+	}
+	want := View{"work-today", "cat:work", "id", true, formatTemplate, nil, "{{.Id}} {{.Description}}"}
+	if !reflect.DeepEqual(views[0], want) {
+		t.Errorf("Got %v, expected %v", views[0], want)
+	}
+}
+
+func TestGetView(t *testing.T) {
+	defer cleanDatabase()
+
+	want := View{"work-today", "cat:work due:<1d", urgencyOrder, false, formatTable, []string{"id", "description"}, ""}
+	if err := SaveView(want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetView("work-today")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %v, expected %v", got, want)
+	}
+
+	if _, err := GetView("missing"); err == nil {
+		t.Error("Expected an error for a missing view")
+	}
+}
+
+func TestDeleteView(t *testing.T) {
+	defer cleanDatabase()
+
+	_ = SaveView(View{Name: "home", Filter: "cat:home", OrderBy: "id", Renderer: formatAligned})
+
+	if err := DeleteView("home"); err != nil {
+		t.Errorf("DeleteView() error = %v", err)
+	}
+	if err := DeleteView("home"); err == nil {
+		t.Error("Expected an error when deleting a missing view")
+	}
+}